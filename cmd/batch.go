@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/batch"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <instruction> (--input-glob <pattern> | --input-jsonl <file>) --out-dir <dir> [flags]",
+	Short: "Run an instruction over many inputs concurrently",
+	Long: `Runs an instruction once per input with a pool of workers and writes one output
+file per input.
+
+With --input-glob, each matching file is sent as the instruction's {{input}}
+and its output keeps the file's path relative to the start of the pattern,
+so 'docs/**/*.md' writes docs/guide/a.md to <out-dir>/guide/a.md. A '**'
+element matches any number of directories.
+
+With --input-jsonl, each line is a JSON object mapping variable names to
+values, such as {"input": "...", "lang": "es"}, and outputs are numbered by
+line. Unlike flags, JSONL values are never read as file paths.
+
+Other flags, including instruction variables, apply to every input. Requests
+failing with a transient provider error are retried up to --max-attempts
+times, progress is printed to stderr and a summary is printed at the end.`,
+	Args:               cobra.MinimumNArgs(1),
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBatch(args[0], args[1:])
+	},
+}
+
+func addBatchFlags(cmd *cobra.Command) {
+	addCachedProviderFlags(cmd)
+	cmd.Flags().String("input-glob", "", "Glob of input files, where '**' matches any number of directories")
+	cmd.Flags().String("input-jsonl", "", "JSONL file with one object of variable values per line")
+	cmd.Flags().String("out-dir", "", "Directory to write one output file per input")
+	cmd.Flags().String("out-ext", "", "Extension of the output files, such as .summary.md (default: same as input, or .md)")
+	cmd.Flags().Int("concurrency", 4, "Number of inputs processed at the same time")
+	cmd.Flags().Int("rate-limit", 0, "Maximum requests per minute, overriding the provider's requests_per_minute")
+	cmd.Flags().Bool("skip-existing", false, "Skip inputs whose output file already exists")
+	cmd.Flags().String("report", "", "Write a JSON report of every input to this file")
+}
+
+// batchReportItem is one input of the JSON report written by --report.
+type batchReportItem struct {
+	Name           string `json:"name"`
+	Output         string `json:"output"`
+	Status         string `json:"status"`
+	DurationMillis int64  `json:"duration_ms,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+type batchReport struct {
+	Instruction    string            `json:"instruction"`
+	Provider       string            `json:"provider"`
+	Model          string            `json:"model"`
+	Succeeded      int               `json:"succeeded"`
+	Failed         int               `json:"failed"`
+	Skipped        int               `json:"skipped"`
+	DurationMillis int64             `json:"duration_ms"`
+	Items          []batchReportItem `json:"items"`
+}
+
+func executeBatch(name string, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	inst, err := instruction.Load(name)
+	if err != nil {
+		return err
+	}
+
+	variables, err := instruction.ParseVariables(inst.SystemText)
+	if err != nil {
+		return err
+	}
+
+	cmd, baseFlags, err := parseInstructionFlags(variables, args, addBatchFlags)
+	if err != nil {
+		return err
+	}
+
+	items, err := batchItems(cmd, variables)
+	if err != nil {
+		return err
+	}
+
+	settings, err := resolveRunSettings(cfg, inst.Meta, cmd)
+	if err != nil {
+		return err
+	}
+
+	llmProvider, err := newCachedProvider(settings)
+	if err != nil {
+		return err
+	}
+
+	var skipped []batch.Item
+	if skipExisting, _ := cmd.Flags().GetBool("skip-existing"); skipExisting {
+		items, skipped = splitExistingOutputs(items)
+	}
+
+	options := batch.Options{
+		RequestsPerMinute: settings.Profile.RequestsPerMinute,
+		Progress:          printBatchProgress,
+	}
+	options.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	if cmd.Flags().Changed("rate-limit") {
+		options.RequestsPerMinute, _ = cmd.Flags().GetInt("rate-limit")
+	}
+
+	var historyMutex sync.Mutex
+
+	process := func(ctx context.Context, item batch.Item) error {
+		resolved, err := resolveBatchItem(variables, baseFlags, item)
+		if err != nil {
+			return err
+		}
+
+		prompt := instruction.Render(inst, variables, resolved)
+		request := provider.Request{
+			Name:     inst.Name,
+			System:   prompt.System,
+			Messages: prompt.Messages,
+			Options:  settings.Options,
+		}
+
+		started := time.Now()
+		response, err := llmProvider.Complete(ctx, request, io.Discard)
+		if err != nil {
+			return err
+		}
+
+		historyMutex.Lock()
+		recordRun(cfg, inst.Name, inst.Meta.Version, settings, request, response, time.Since(started))
+		historyMutex.Unlock()
+
+		file, err := createOutputFile(item.OutputPath, false)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write([]byte(response.Text)); err != nil {
+			file.Discard()
+			return fmt.Errorf("failed to write %s: %w", item.OutputPath, err)
+		}
+		return file.Commit()
+	}
+
+	ctx, stopSignalHandling := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignalHandling()
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d inputs with existing output\n", len(skipped))
+	}
+	fmt.Fprintf(os.Stderr, "Running %s on %d inputs with %s/%s\n", inst.Name, len(items), settings.ProfileName, settings.Model)
+
+	report := batch.Run(ctx, items, options, process)
+
+	printBatchSummary(report, len(skipped))
+
+	if reportPath, _ := cmd.Flags().GetString("report"); reportPath != "" {
+		if err := writeBatchReport(reportPath, inst.Name, settings, report, skipped); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return &exitError{code: ExitInterrupted, err: errInterrupted}
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d inputs failed", report.Failed, len(items))
+	}
+	return nil
+}
+
+// batchItems builds the items from --input-glob or --input-jsonl, with their
+// output paths under --out-dir.
+func batchItems(cmd *cobra.Command, variables []instruction.Variable) ([]batch.Item, error) {
+	inputGlob, _ := cmd.Flags().GetString("input-glob")
+	inputJSONL, _ := cmd.Flags().GetString("input-jsonl")
+	outDir, _ := cmd.Flags().GetString("out-dir")
+	outExtension, _ := cmd.Flags().GetString("out-ext")
+
+	if (inputGlob == "") == (inputJSONL == "") {
+		return nil, fmt.Errorf("use exactly one of --input-glob <pattern> or --input-jsonl <file>")
+	}
+	if outDir == "" {
+		return nil, fmt.Errorf("--out-dir is required")
+	}
+
+	var items []batch.Item
+
+	if inputGlob != "" {
+		if !hasVariableOption(variables, "input") {
+			return nil, fmt.Errorf("--input-glob sends each file as {{input}}, but this instruction has no input variable\n\nUse --input-jsonl to set other variables per input")
+		}
+
+		matches, err := batch.Glob(inputGlob)
+		if err != nil {
+			return nil, err
+		}
+
+		base := batch.GlobBase(inputGlob)
+		for _, match := range matches {
+			relativePath, err := filepath.Rel(base, match)
+			if err != nil {
+				relativePath = filepath.Base(match)
+			}
+			if outExtension != "" {
+				relativePath = strings.TrimSuffix(relativePath, filepath.Ext(relativePath)) + outExtension
+			}
+			items = append(items, batch.Item{
+				Name:       match,
+				InputPath:  match,
+				OutputPath: filepath.Join(outDir, relativePath),
+			})
+		}
+	} else {
+		rows, err := batch.ReadJSONL(inputJSONL)
+		if err != nil {
+			return nil, err
+		}
+
+		if outExtension == "" {
+			outExtension = ".md"
+		}
+
+		width := len(fmt.Sprint(len(rows)))
+		for i, row := range rows {
+			for option := range row {
+				if !hasVariableOption(variables, option) {
+					return nil, fmt.Errorf("%s item %d: '%s' is not a variable of this instruction", inputJSONL, i+1, option)
+				}
+			}
+			items = append(items, batch.Item{
+				Name:       fmt.Sprintf("%s:%d", inputJSONL, i+1),
+				Variables:  row,
+				OutputPath: filepath.Join(outDir, fmt.Sprintf("%0*d%s", width, i+1, outExtension)),
+			})
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no inputs found")
+	}
+
+	inputsByOutput := make(map[string]string, len(items))
+	for _, item := range items {
+		if other, exists := inputsByOutput[item.OutputPath]; exists {
+			return nil, fmt.Errorf("inputs %s and %s would both write %s\n\nUse an --out-ext that keeps their extensions apart, or rename one of them", other, item.Name, item.OutputPath)
+		}
+		inputsByOutput[item.OutputPath] = item.Name
+	}
+
+	return items, nil
+}
+
+// resolveBatchItem resolves the variables of one input: the content of its
+// input file or its JSONL values, over the flags shared by every input. JSONL
+// values are used as-is, so a value that happens to name a file is not
+// replaced by the file's content.
+func resolveBatchItem(variables []instruction.Variable, baseFlags map[string]string, item batch.Item) (map[string]string, error) {
+	resolver := instruction.Resolver{Variables: variables, Flags: baseFlags, Values: make(map[string]string)}
+
+	if item.InputPath != "" {
+		content, err := os.ReadFile(item.InputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		resolver.Stdin = string(content)
+	}
+
+	for option, value := range item.Variables {
+		if option == "input" {
+			resolver.Stdin = value
+		} else {
+			resolver.Values[option] = value
+		}
+	}
+
+	return resolver.Resolve()
+}
+
+func splitExistingOutputs(items []batch.Item) ([]batch.Item, []batch.Item) {
+	var pending, existing []batch.Item
+	for _, item := range items {
+		if _, err := os.Stat(item.OutputPath); err == nil {
+			existing = append(existing, item)
+		} else {
+			pending = append(pending, item)
+		}
+	}
+	return pending, existing
+}
+
+func printBatchProgress(finished int, total int, result batch.Result) {
+	if result.Err != nil {
+		fmt.Fprintf(os.Stderr, "[%d/%d] failed %s: %v\n", finished, total, result.Item.Name, result.Err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d/%d] ok %s -> %s (%s)\n", finished, total, result.Item.Name, result.Item.OutputPath, result.Duration.Round(100*time.Millisecond))
+}
+
+func printBatchSummary(report batch.Report, skipped int) {
+	fmt.Fprintf(os.Stderr, "\nFinished in %s: %d succeeded, %d failed, %d skipped\n",
+		report.Duration.Round(100*time.Millisecond), report.Succeeded, report.Failed, skipped)
+
+	for _, result := range report.Results {
+		if result.Err != nil && !errors.Is(result.Err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "  failed: %s: %v\n", result.Item.Name, result.Err)
+		}
+	}
+}
+
+func writeBatchReport(path string, instructionName string, settings runSettings, report batch.Report, skipped []batch.Item) error {
+	summary := batchReport{
+		Instruction:    instructionName,
+		Provider:       settings.ProfileName,
+		Model:          settings.Model,
+		Succeeded:      report.Succeeded,
+		Failed:         report.Failed,
+		Skipped:        len(skipped),
+		DurationMillis: report.Duration.Milliseconds(),
+	}
+
+	for _, result := range report.Results {
+		item := batchReportItem{
+			Name:           result.Item.Name,
+			Output:         result.Item.OutputPath,
+			Status:         "ok",
+			DurationMillis: result.Duration.Milliseconds(),
+		}
+		if result.Err != nil {
+			item.Status = "failed"
+			item.Error = result.Err.Error()
+		}
+		summary.Items = append(summary.Items, item)
+	}
+
+	for _, skippedItem := range skipped {
+		summary.Items = append(summary.Items, batchReportItem{Name: skippedItem.Name, Output: skippedItem.OutputPath, Status: "skipped"})
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	file, err := createOutputFile(path, false)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Discard()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return file.Commit()
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+}
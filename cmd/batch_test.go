@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/batch"
+	"github.com/yourusername/gliik/internal/instruction"
+)
+
+func TestResolveBatchItem_JSONLValuesAreNotReadAsFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("README.md", []byte("file content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile("glossary.txt", []byte("glossary content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	variables, err := instruction.ParseVariables("Translate to {{lang}} using {{glossary}}: {{input}}")
+	if err != nil {
+		t.Fatalf("failed to parse variables: %v", err)
+	}
+
+	item := batch.Item{Name: "inputs.jsonl:1", Variables: map[string]string{"input": "Hello", "lang": "README.md"}}
+	resolved, err := resolveBatchItem(variables, map[string]string{"glossary": "glossary.txt"}, item)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resolved["{{lang}}"] != "README.md" {
+		t.Errorf("expected the JSONL value to be used as-is, got %q", resolved["{{lang}}"])
+	}
+	if resolved["{{glossary}}"] != "glossary content" {
+		t.Errorf("expected the flag naming a file to be read, got %q", resolved["{{glossary}}"])
+	}
+	if resolved["{{input}}"] != "Hello" {
+		t.Errorf("expected the input from the JSONL row, got %q", resolved["{{input}}"])
+	}
+}
+
+func TestResolveBatchItem_InputFile(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "a.md")
+	if err := os.WriteFile(inputPath, []byte("Document"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	variables, err := instruction.ParseVariables("Summarize: {{input}}")
+	if err != nil {
+		t.Fatalf("failed to parse variables: %v", err)
+	}
+
+	resolved, err := resolveBatchItem(variables, nil, batch.Item{Name: inputPath, InputPath: inputPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved["{{input}}"] != "Document" {
+		t.Errorf("expected the input file's content, got %q", resolved["{{input}}"])
+	}
+}
+
+func TestBatchItems_RejectsCollidingOutputs(t *testing.T) {
+	inputDir := t.TempDir()
+	for _, name := range []string{"a.md", "a.txt"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name), []byte("Document"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	variables, err := instruction.ParseVariables("Summarize: {{input}}")
+	if err != nil {
+		t.Fatalf("failed to parse variables: %v", err)
+	}
+
+	args := []string{"--input-glob", filepath.Join(inputDir, "*"), "--out-dir", t.TempDir()}
+
+	flagCmd, _, err := parseInstructionFlags(variables, args, addBatchFlags)
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	if _, err := batchItems(flagCmd, variables); err != nil {
+		t.Fatalf("expected outputs keeping their extensions not to collide, got %v", err)
+	}
+
+	flagCmd, _, err = parseInstructionFlags(variables, append(args, "--out-ext", ".summary.md"), addBatchFlags)
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	_, err = batchItems(flagCmd, variables)
+	if err == nil {
+		t.Fatal("expected an error for inputs writing the same output")
+	}
+	if !strings.Contains(err.Error(), "a.md") || !strings.Contains(err.Error(), "a.txt") {
+		t.Errorf("expected the error to name both inputs, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/cache"
+	"github.com/yourusername/gliik/internal/config"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the response cache",
+	Long:  `Manages the responses cached by 'gliik run --cache' or instructions with 'cache: true'.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache size and age",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		ttl, err := configuredCacheTTL(cfg)
+		if err != nil {
+			return err
+		}
+
+		stats, err := cache.GetStats(ttl)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Directory: %s\n", cache.GetCacheDir())
+		fmt.Printf("Entries:   %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:      %s\n", formatBytes(stats.Bytes))
+		if ttl > 0 {
+			fmt.Printf("TTL:       %s\n", ttl)
+		} else {
+			fmt.Println("TTL:       none")
+		}
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:    %s\n", stats.Oldest.Local().Format("2006-01-02 15:04:05"))
+			fmt.Printf("Newest:    %s\n", stats.Newest.Local().Format("2006-01-02 15:04:05"))
+		}
+
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached responses",
+	Long:  `Removes every cached response, or only those older than the configured TTL with --expired.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expiredOnly, _ := cmd.Flags().GetBool("expired")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		ttl, err := configuredCacheTTL(cfg)
+		if err != nil {
+			return err
+		}
+
+		if expiredOnly && ttl == 0 {
+			return fmt.Errorf("--expired requires 'cache.ttl' to be set in config.yaml")
+		}
+
+		removed, err := cache.Clear(expiredOnly, ttl)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Removed %d cached responses\n", removed)
+		return nil
+	},
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	divisor, exponent := int64(unit), 0
+	for remaining := size / unit; remaining >= unit; remaining /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
+
+func init() {
+	cacheClearCmd.Flags().Bool("expired", false, "Only remove responses older than the configured TTL")
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/atomicfile"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+	"github.com/yourusername/gliik/internal/session"
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat <instruction>",
+	Short: "Chat interactively, starting from an instruction",
+	Long: `Starts a conversation seeded with the resolved instruction, streams the reply and then reads follow-up messages from the prompt.
+
+Variables are resolved from CLI flags. When the instruction takes {{input}} and no flag provides it, the first message typed at the prompt is used as the input.
+
+Chat commands:
+  /retry         Regenerate the last reply
+  /reset         Drop the follow-ups and return to the first reply
+  /save <file>   Save the transcript in the multi-message instruction format
+  /exit          End the chat (Ctrl-D also works)
+
+With --session, the conversation is saved after every reply and an existing session is resumed where it left off.
+
+Ctrl-C cancels the reply being streamed without leaving the chat. When the first reply fails or is cancelled, /retry generates it again.`,
+	Args:               cobra.MinimumNArgs(1),
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeChat(args[0], args[1:])
+	},
+}
+
+// chatConversation holds the message history of a chat. Providers are
+// stateless, so the full history is sent with every turn. When session is set
+// the history is saved after every change. firstReplyLength is the number of
+// messages up to and including the first reply, which /reset returns to;
+// counting them avoids mistaking an example reply of a multi-message
+// instruction for the model's.
+type chatConversation struct {
+	llmProvider      provider.LLMProvider
+	output           io.Writer
+	system           string
+	options          provider.GenerationOptions
+	messages         []provider.Message
+	firstReplyLength int
+	instruction      *instruction.Instruction
+	settings         runSettings
+	resolved         map[string]string
+	session          *session.Session
+	config           *config.Config
+	timing           *timedProvider
+	showStats        bool
+}
+
+func executeChat(name string, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	inst, err := instruction.Load(name)
+	if err != nil {
+		return err
+	}
+
+	variables, err := instruction.ParseVariables(inst.SystemText)
+	if err != nil {
+		return err
+	}
+
+	cmd, flags, err := parseInstructionFlags(variables, args, addChatFlags)
+	if err != nil {
+		return err
+	}
+
+	settings, err := resolveRunSettings(cfg, inst.Meta, cmd)
+	if err != nil {
+		return err
+	}
+
+	llmProvider, err := newProvider(settings)
+	if err != nil {
+		return err
+	}
+
+	promptLines := bufio.NewScanner(os.Stdin)
+	promptLines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	timing := &timedProvider{inner: llmProvider}
+	showStats, _ := cmd.Flags().GetBool("stats")
+
+	conversation := &chatConversation{
+		llmProvider: timing,
+		output:      os.Stdout,
+		options:     settings.Options,
+		instruction: inst,
+		settings:    settings,
+		config:      cfg,
+		timing:      timing,
+		showStats:   showStats,
+	}
+
+	sessionName, _ := cmd.Flags().GetString("session")
+	if sessionName != "" {
+		conversation.session, err = openSession(sessionName, inst)
+		if err != nil {
+			return err
+		}
+	}
+
+	if conversation.session != nil && len(conversation.session.Messages) > 0 {
+		conversation.resume()
+		fmt.Fprintf(os.Stderr, "Resumed session '%s' (%d messages)\n", sessionName, len(conversation.messages))
+	} else {
+		firstInput := ""
+		if needsInputFromPrompt(variables, flags) {
+			line, ok := readChatLine(promptLines)
+			if !ok {
+				return nil
+			}
+			firstInput = line
+		}
+
+		resolver := instruction.Resolver{
+			Variables: variables,
+			Stdin:     firstInput,
+			Flags:     flags,
+		}
+
+		conversation.resolved, err = resolver.Resolve()
+		if err != nil {
+			return err
+		}
+
+		prompt := instruction.Render(inst, variables, conversation.resolved)
+		conversation.system = prompt.System
+		conversation.messages = prompt.Messages
+
+		conversation.firstReply()
+	}
+
+	conversation.converse(promptLines)
+	return nil
+}
+
+// firstReply generates the reply to the rendered instruction. A failed or
+// cancelled reply is reported without ending the chat, so /retry can
+// generate it again.
+func (c *chatConversation) firstReply() {
+	c.firstReplyLength = len(c.messages) + 1
+
+	reply, err := c.reply(c.messages)
+	if err != nil {
+		reportChatError(err)
+		fmt.Fprintln(os.Stderr, "Use /retry to generate the first reply")
+		return
+	}
+	c.record(reply)
+}
+
+// resume continues the conversation saved in the session, keeping its first
+// reply as the point /reset returns to.
+func (c *chatConversation) resume() {
+	c.system = c.session.System
+	c.messages = c.session.Messages
+	c.firstReplyLength = c.session.FirstReplyLength
+	if c.firstReplyLength == 0 || c.firstReplyLength > len(c.messages) {
+		c.firstReplyLength = len(c.messages)
+	}
+}
+
+// converse reads follow-up messages and chat commands from promptLines until
+// /exit or the end of input.
+func (c *chatConversation) converse(promptLines *bufio.Scanner) {
+	for {
+		line, ok := readChatLine(promptLines)
+		if !ok {
+			return
+		}
+
+		if !strings.HasPrefix(line, "/") {
+			turn := provider.Message{Role: provider.RoleUser, Content: line}
+			history := append(append([]provider.Message(nil), c.messages...), turn)
+			reply, err := c.reply(history)
+			if err != nil {
+				reportChatError(err)
+				continue
+			}
+			c.record(turn, reply)
+			continue
+		}
+
+		command, argument, _ := strings.Cut(line, " ")
+		switch command {
+		case "/exit", "/quit":
+			return
+		case "/retry":
+			c.retry()
+		case "/reset":
+			c.messages = c.messages[:min(c.firstReplyLength, len(c.messages))]
+			c.persist()
+			fmt.Fprintln(os.Stderr, "Conversation reset to the first reply")
+		case "/save":
+			if err := c.save(strings.TrimSpace(argument)); err != nil {
+				reportChatError(err)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown command: %s (available: /retry, /reset, /save <file>, /exit)\n", command)
+		}
+	}
+}
+
+// retry regenerates the last reply. When the conversation does not end with
+// a reply, such as a resumed session whose last request failed, a reply to
+// the last message is generated instead.
+func (c *chatConversation) retry() {
+	history := c.messages
+	if len(history) > 0 && history[len(history)-1].Role == provider.RoleAssistant {
+		history = history[:len(history)-1]
+	}
+
+	reply, err := c.reply(history)
+	if err != nil {
+		reportChatError(err)
+		return
+	}
+
+	c.messages = history
+	c.record(reply)
+}
+
+// reply streams a reply to history and returns it without changing the
+// conversation, so a failed request leaves the history as it was.
+func (c *chatConversation) reply(history []provider.Message) (provider.Message, error) {
+	request := provider.Request{
+		Name:     c.instruction.Name,
+		System:   c.system,
+		Messages: history,
+		Options:  c.options,
+	}
+
+	output := &lineTrackingWriter{writer: c.output}
+	response, err := completeUntilInterrupted(c.llmProvider, request, output)
+	if err != nil {
+		return provider.Message{}, err
+	}
+	output.finishLine()
+
+	if c.showStats {
+		printRunStats(os.Stderr, c.config, c.settings, c.timing, response)
+	}
+
+	return provider.Message{Role: provider.RoleAssistant, Content: response.Text}, nil
+}
+
+// record appends turns to the history and saves the session.
+func (c *chatConversation) record(turns ...provider.Message) {
+	c.messages = append(c.messages, turns...)
+	c.persist()
+}
+
+// persist saves the history to the session. A failed write only prints a
+// warning: the conversation goes on in memory and the next change tries
+// again.
+func (c *chatConversation) persist() {
+	if c.session == nil {
+		return
+	}
+	if c.firstReplyLength > 0 {
+		c.session.FirstReplyLength = c.firstReplyLength
+	}
+	if err := recordSession(c.session, c.instruction, c.settings, c.resolved, c.system, c.messages); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
+	}
+}
+
+func (c *chatConversation) save(path string) error {
+	if path == "" {
+		return fmt.Errorf("usage: /save <file>")
+	}
+
+	file, err := atomicfile.Create(path, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	if _, err := io.WriteString(file, instruction.FormatMessages(c.system, c.messages)); err != nil {
+		file.Discard()
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	if err := file.Commit(); err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Saved transcript to %s\n", path)
+	return nil
+}
+
+func needsInputFromPrompt(variables []instruction.Variable, flags map[string]string) bool {
+	for _, variable := range variables {
+		acceptsInput := false
+		providedByFlag := false
+		for _, option := range variable.Options {
+			if option == "input" {
+				acceptsInput = true
+			} else if _, exists := flags[option]; exists {
+				providedByFlag = true
+			}
+		}
+		if acceptsInput && !providedByFlag {
+			return true
+		}
+	}
+	return false
+}
+
+func readChatLine(promptLines *bufio.Scanner) (string, bool) {
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !promptLines.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return "", false
+		}
+		if line := strings.TrimSpace(promptLines.Text()); line != "" {
+			return line, true
+		}
+	}
+}
+
+func reportChatError(err error) {
+	if errors.Is(err, errInterrupted) {
+		fmt.Fprintln(os.Stderr, "Reply cancelled")
+		return
+	}
+	fmt.Fprintln(os.Stderr, formatError(err))
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+}
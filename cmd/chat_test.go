@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+	"github.com/yourusername/gliik/internal/session"
+)
+
+func TestChatFlags_RejectRunOnlyFlags(t *testing.T) {
+	for _, flag := range []string{"--dry-run", "--json", "--output", "--tee", "--append", "--context", "--context-selector"} {
+		if _, _, err := parseInstructionFlags(nil, []string{flag}, addChatFlags); err == nil {
+			t.Errorf("expected chat to reject %s", flag)
+		}
+	}
+}
+
+func TestChatConversation_ResetAndRetryWithExampleTurns(t *testing.T) {
+	messages, err := instruction.ParseMessages("## user\nExample question\n\n## assistant\nExample answer\n\n## user\n{{input}}\n")
+	if err != nil {
+		t.Fatalf("failed to parse messages: %v", err)
+	}
+	inst := &instruction.Instruction{Name: "fewshot", Messages: messages}
+	prompt := instruction.Render(inst, nil, map[string]string{"{{input}}": "Real question"})
+
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{},
+		output:      io.Discard,
+		system:      prompt.System,
+		messages:    prompt.Messages,
+		instruction: inst,
+	}
+	reply, err := conversation.reply(conversation.messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conversation.record(reply)
+	conversation.firstReplyLength = len(conversation.messages)
+	firstReply := conversation.messages[3]
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("Follow-up\n/reset\n")))
+
+	if len(conversation.messages) != 4 {
+		t.Fatalf("expected /reset to keep the example turns, the input and the first reply, got %d messages", len(conversation.messages))
+	}
+	if conversation.messages[2].Content != "Real question" || conversation.messages[3] != firstReply {
+		t.Errorf("expected /reset to return to the first reply, got %+v", conversation.messages[2:])
+	}
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("/retry\n")))
+
+	if len(conversation.messages) != 4 || conversation.messages[1].Content != "Example answer" {
+		t.Fatalf("expected /retry to keep the example turns, got %+v", conversation.messages)
+	}
+	if conversation.messages[3].Role != provider.RoleAssistant || conversation.messages[3].Content != firstReply.Content {
+		t.Errorf("expected /retry to regenerate the first reply, got %+v", conversation.messages[3])
+	}
+}
+
+func TestChatConversation_RetryWithoutReply(t *testing.T) {
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{},
+		output:      io.Discard,
+		messages:    provider.UserMessage("Question"),
+		instruction: &instruction.Instruction{Name: "resumed"},
+	}
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("/retry\n")))
+
+	if len(conversation.messages) != 2 || conversation.messages[0].Content != "Question" || conversation.messages[1].Role != provider.RoleAssistant {
+		t.Errorf("expected /retry to keep the question and add a reply, got %+v", conversation.messages)
+	}
+}
+
+func TestChatConversation_FailedReplyKeepsHistory(t *testing.T) {
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{ErrorStatus: 400},
+		output:      io.Discard,
+		messages:    []provider.Message{{Role: provider.RoleUser, Content: "Question"}, {Role: provider.RoleAssistant, Content: "Answer"}},
+		instruction: &instruction.Instruction{Name: "failing"},
+	}
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("Follow-up\n/retry\n")))
+
+	if len(conversation.messages) != 2 || conversation.messages[1].Content != "Answer" {
+		t.Errorf("expected failed replies to leave the history untouched, got %+v", conversation.messages)
+	}
+}
+
+func TestChatConversation_FailedSaveKeepsTurns(t *testing.T) {
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{},
+		output:      io.Discard,
+		messages:    []provider.Message{{Role: provider.RoleUser, Content: "Question"}, {Role: provider.RoleAssistant, Content: "Answer"}},
+		instruction: &instruction.Instruction{Name: "unsaved"},
+		session:     &session.Session{Name: "../invalid"},
+	}
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("Follow-up\n/retry\n")))
+
+	roles := []string{provider.RoleUser, provider.RoleAssistant, provider.RoleUser, provider.RoleAssistant}
+	if len(conversation.messages) != len(roles) {
+		t.Fatalf("expected the follow-up and its reply to be kept, got %+v", conversation.messages)
+	}
+	for i, role := range roles {
+		if conversation.messages[i].Role != role {
+			t.Errorf("expected message %d to be %s, got %+v", i, role, conversation.messages)
+		}
+	}
+	if conversation.messages[2].Content != "Follow-up" {
+		t.Errorf("expected the follow-up to be kept, got %+v", conversation.messages[2])
+	}
+}
+
+func TestChatConversation_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.md")
+	if err := os.WriteFile(path, []byte("Old transcript"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	conversation := &chatConversation{
+		system:   "Be brief.",
+		messages: []provider.Message{{Role: provider.RoleUser, Content: "Question"}, {Role: provider.RoleAssistant, Content: "Answer"}},
+	}
+	if err := conversation.save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != instruction.FormatMessages(conversation.system, conversation.messages) {
+		t.Errorf("expected the transcript in the multi-message format, got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the file's permissions to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestChatConversation_ResetAfterResume(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	saved := &session.Session{
+		Name:        "resumed",
+		Instruction: "resumed",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Question"},
+			{Role: provider.RoleAssistant, Content: "Answer"},
+			{Role: provider.RoleUser, Content: "Earlier follow-up"},
+			{Role: provider.RoleAssistant, Content: "Earlier reply"},
+		},
+		FirstReplyLength: 2,
+	}
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{},
+		output:      io.Discard,
+		instruction: &instruction.Instruction{Name: "resumed"},
+		session:     saved,
+	}
+	conversation.resume()
+
+	conversation.converse(bufio.NewScanner(strings.NewReader("Follow-up\n/reset\n")))
+
+	if len(conversation.messages) != 2 || conversation.messages[1].Content != "Answer" {
+		t.Fatalf("expected /reset to return to the first reply, got %+v", conversation.messages)
+	}
+
+	reloaded, err := session.Load("resumed")
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if len(reloaded.Messages) != 2 || reloaded.FirstReplyLength != 2 {
+		t.Errorf("expected the reset session to be saved, got %d messages and first reply length %d", len(reloaded.Messages), reloaded.FirstReplyLength)
+	}
+}
+
+func TestChatConversation_RetryFailedFirstReply(t *testing.T) {
+	conversation := &chatConversation{
+		llmProvider: &provider.MockProvider{ErrorStatus: 503},
+		output:      io.Discard,
+		messages:    provider.UserMessage("Question"),
+		instruction: &instruction.Instruction{Name: "failing"},
+	}
+
+	conversation.firstReply()
+
+	if len(conversation.messages) != 1 {
+		t.Fatalf("expected the failed first reply to leave the prompt, got %+v", conversation.messages)
+	}
+
+	conversation.llmProvider = &provider.MockProvider{}
+	conversation.converse(bufio.NewScanner(strings.NewReader("/reset\n/retry\nFollow-up\n/reset\n")))
+
+	if len(conversation.messages) != 2 || conversation.messages[1].Role != provider.RoleAssistant {
+		t.Errorf("expected /retry to generate the first reply and /reset to return to it, got %+v", conversation.messages)
+	}
+}
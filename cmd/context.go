@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/pipeline"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// buildContext expands the [link: path] references of the context file at
+// contextPath into one delimited text. When selectorName is set, that
+// instruction receives the context file as input, and the name of the
+// instruction being run as its --intent flag when it has one, and answers
+// which links to include. The selector runs with selectorArgs, the run's
+// provider flags. Missing files and selected [link: path] references that
+// are not links of the context file are reported as warnings on stderr; other
+// lines of the selector's answer are ignored.
+func buildContext(cfg *config.Config, contextPath string, selectorName string, intent string, selectorArgs []string) (string, error) {
+	contextFile, err := instruction.LoadContextFile(contextPath)
+	if err != nil {
+		return "", err
+	}
+
+	links := contextFile.Links
+	if selectorName != "" && len(links) > 0 {
+		links, err = selectLinks(cfg, contextFile, selectorName, intent, selectorArgs)
+		if err != nil {
+			return "", fmt.Errorf("context selector '%s' failed: %w", selectorName, err)
+		}
+	}
+
+	expanded, missing := contextFile.Expand(links)
+	for _, path := range missing {
+		fmt.Fprintf(os.Stderr, "Warning: linked file not found: %s\n", path)
+	}
+
+	return expanded, nil
+}
+
+func selectLinks(cfg *config.Config, contextFile *instruction.ContextFile, selectorName string, intent string, selectorArgs []string) ([]string, error) {
+	selector, err := instruction.Load(selectorName)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := instruction.ParseVariables(selector.SystemText)
+	if err != nil {
+		return nil, err
+	}
+
+	step := pipeline.Step{Instruction: selectorName, Args: selectorArgs}
+	if hasVariableOption(variables, "intent") {
+		step.Args = append(step.Args, "--intent", intent)
+	}
+
+	discard := func(llmProvider provider.LLMProvider, request provider.Request) (provider.Response, error) {
+		return completeUntilInterrupted(llmProvider, request, &lineTrackingWriter{writer: io.Discard})
+	}
+
+	response, err := runPipelineStep(cfg, step, contextFile.Content, nil, discard)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectedLinks(contextFile, response.Text), nil
+}
+
+// selectedLinks returns the links of contextFile listed in the selector's
+// answer.
+func selectedLinks(contextFile *instruction.ContextFile, answer string) []string {
+	linked := make(map[string]bool)
+	for _, link := range contextFile.Links {
+		linked[link] = true
+	}
+
+	var selected []string
+	for _, choice := range instruction.ParseSelection(answer) {
+		if linked[choice.Path] {
+			selected = append(selected, choice.Path)
+		} else if choice.Link {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring selection '%s': not a link in %s\n", choice.Path, contextFile.Path)
+		}
+	}
+
+	return selected
+}
+
+// providerFlagArgs returns the --provider, --profile and --model flags set on
+// cmd, so that the instructions a run sends on its behalf use the same
+// provider.
+func providerFlagArgs(cmd *cobra.Command) []string {
+	var args []string
+	for _, flagName := range []string{"provider", "profile", "model"} {
+		if cmd.Flags().Changed(flagName) {
+			value, _ := cmd.Flags().GetString(flagName)
+			args = append(args, "--"+flagName, value)
+		}
+	}
+	return args
+}
+
+// joinInputs combines the expanded context with piped stdin, context first.
+func joinInputs(context string, stdin string) string {
+	if stdin == "" {
+		return context
+	}
+	return context + "\n" + stdin
+}
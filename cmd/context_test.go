@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/instruction"
+)
+
+func TestProviderFlagArgs(t *testing.T) {
+	flagCmd, _, err := parseInstructionFlags(nil, []string{"--provider", "ollama", "--model", "llama3.2", "--temperature", "0.2"}, addRunFlags)
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	expected := []string{"--provider", "ollama", "--model", "llama3.2"}
+	if args := providerFlagArgs(flagCmd); !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestSelectedLinks_IgnoresCommentary(t *testing.T) {
+	contextFile := &instruction.ContextFile{Path: "context.md", Links: []string{"docs/metrics.md", "notes.md"}}
+	answer := "Here are the relevant files:\n\n- docs/metrics.md\n[link: notes.md]\n[link: missing.md]\nThey cover revenue.\n"
+
+	expected := []string{"docs/metrics.md", "notes.md"}
+	if selected := selectedLinks(contextFile, answer); !reflect.DeepEqual(selected, expected) {
+		t.Errorf("expected %v, got %v", expected, selected)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// dryRunReport is the JSON form of `gliik run --dry-run --json`.
+type dryRunReport struct {
+	Instruction        string                     `json:"instruction"`
+	InstructionVersion string                     `json:"instruction_version"`
+	Provider           string                     `json:"provider"`
+	ProviderType       string                     `json:"provider_type"`
+	Model              string                     `json:"model"`
+	PromptHash         string                     `json:"prompt_hash"`
+	Options            provider.GenerationOptions `json:"options"`
+	System             string                     `json:"system"`
+	Messages           []provider.Message         `json:"messages"`
+	Output             string                     `json:"output,omitempty"`
+}
+
+// printDryRun writes the provider, model, generation options and messages a
+// run would send, and the file it would write to, without calling the
+// provider.
+func printDryRun(w io.Writer, inst *instruction.Instruction, settings runSettings, request provider.Request, target outputTarget, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(dryRunReport{
+			Instruction:        inst.Name,
+			InstructionVersion: inst.Meta.Version,
+			Provider:           settings.ProfileName,
+			ProviderType:       settings.Profile.Type,
+			Model:              settings.Model,
+			PromptHash:         provider.HashPrompt(request.System, request.Messages),
+			Options:            request.Options,
+			System:             request.System,
+			Messages:           request.Messages,
+			Output:             target.Path,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal dry run: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	providerName := settings.ProfileName
+	if settings.Profile.Type != settings.ProfileName {
+		providerName = fmt.Sprintf("%s (%s)", settings.ProfileName, settings.Profile.Type)
+	}
+
+	fmt.Fprintf(w, "Instruction: %s v%s\n", inst.Name, inst.Meta.Version)
+	fmt.Fprintf(w, "Provider:    %s\n", providerName)
+	fmt.Fprintf(w, "Model:       %s\n", settings.Model)
+	fmt.Fprintf(w, "Options:     %s\n", formatGenerationOptions(request.Options))
+	fmt.Fprintf(w, "Prompt hash: %s\n", provider.HashPrompt(request.System, request.Messages))
+	if target.Path != "" {
+		fmt.Fprintf(w, "Output:      %s\n", target.Path)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprint(w, instruction.FormatMessages(request.System, request.Messages))
+	return nil
+}
+
+func formatGenerationOptions(options provider.GenerationOptions) string {
+	if options.IsZero() {
+		return "provider defaults"
+	}
+
+	var parts []string
+	if options.Temperature != nil {
+		parts = append(parts, "temperature="+strconv.FormatFloat(*options.Temperature, 'g', -1, 64))
+	}
+	if options.MaxTokens != nil {
+		parts = append(parts, "max_tokens="+strconv.Itoa(*options.MaxTokens))
+	}
+	if options.TopP != nil {
+		parts = append(parts, "top_p="+strconv.FormatFloat(*options.TopP, 'g', -1, 64))
+	}
+	for _, stop := range options.Stop {
+		parts = append(parts, "stop="+strconv.Quote(stop))
+	}
+	if options.Seed != nil {
+		parts = append(parts, "seed="+strconv.Itoa(*options.Seed))
+	}
+	return strings.Join(parts, " ")
+}
@@ -39,6 +39,10 @@ var editCmd = &cobra.Command{
 			return fmt.Errorf("failed to open editor: %w", err)
 		}
 
+		if _, err := instruction.Load(name); err != nil {
+			return fmt.Errorf("instruction '%s' saved with errors: %w", name, err)
+		}
+
 		return nil
 	},
 }
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// ExitInterrupted is the process exit code used when a run is cancelled with
+// Ctrl-C, following the shell convention of 128 + SIGINT.
+const ExitInterrupted = 130
+
+// Process exit codes for provider failures, so scripts can tell them apart
+// from other errors, which exit with 1.
+const (
+	ExitAuth          = 3
+	ExitRateLimited   = 4
+	ExitUnavailable   = 5
+	ExitContextLength = 6
+	ExitBadRequest    = 7
+)
+
+var errInterrupted = errors.New("interrupted")
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func exitCodeFor(err error) int {
+	var codedError *exitError
+	if errors.As(err, &codedError) {
+		return codedError.code
+	}
+
+	switch {
+	case errors.Is(err, provider.ErrAuth):
+		return ExitAuth
+	case errors.Is(err, provider.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, provider.ErrUnavailable):
+		return ExitUnavailable
+	case errors.Is(err, provider.ErrContextLength):
+		return ExitContextLength
+	case errors.Is(err, provider.ErrBadRequest):
+		return ExitBadRequest
+	}
+	return 1
+}
+
+// providerDisplayNames are the names shown in error messages for the built-in
+// provider types.
+var providerDisplayNames = map[string]string{
+	"anthropic": "Anthropic",
+	"openai":    "OpenAI",
+	"gemini":    "Gemini",
+	"ollama":    "Ollama",
+}
+
+// apiKeyPages are the pages where the API keys of the built-in provider types
+// are managed.
+var apiKeyPages = map[string]string{
+	"anthropic": "https://console.anthropic.com/settings/keys",
+	"openai":    "https://platform.openai.com/api-keys",
+	"gemini":    "https://aistudio.google.com/app/apikey",
+}
+
+// formatError renders err for the terminal. Provider API errors are explained
+// according to their kind, after the context they were wrapped with,
+// connection errors are followed by a hint, and other errors are printed with
+// an "Error: " prefix unless they carry one.
+func formatError(err error) string {
+	var connectionError *provider.ConnectionError
+	if errors.As(err, &connectionError) {
+		return "Error: " + err.Error() + "\n\n" + connectionHint(connectionError)
+	}
+
+	var apiError *provider.APIError
+	if !errors.As(err, &apiError) {
+		if strings.HasPrefix(err.Error(), "Error: ") {
+			return err.Error()
+		}
+		return "Error: " + err.Error()
+	}
+
+	explanation := explainAPIError(apiError)
+	context, wrapped := strings.CutSuffix(err.Error(), apiError.Error())
+	if wrapped && strings.TrimSpace(context) != "" {
+		return strings.TrimSpace(context) + "\n" + explanation
+	}
+	return explanation
+}
+
+func explainAPIError(apiError *provider.APIError) string {
+	name := providerDisplayNames[apiError.Provider]
+	if name == "" {
+		name = apiError.Provider
+	}
+	body := strings.TrimSpace(apiError.Body)
+
+	switch {
+	case errors.Is(apiError, provider.ErrAuth):
+		message := fmt.Sprintf("Error: Invalid %s API key\n\nThe API key you provided is invalid, expired or not allowed to access this resource.", name)
+		if page, ok := apiKeyPages[apiError.Provider]; ok {
+			message += "\nPlease check your API key at: " + page
+		}
+		return message
+	case errors.Is(apiError, provider.ErrRateLimited):
+		return fmt.Sprintf("Error: %s rate limit exceeded\n\nYou have exceeded your API rate limit.\nPlease wait a moment and try again.", name)
+	case errors.Is(apiError, provider.ErrUnavailable):
+		message := fmt.Sprintf("Error: %s service unavailable\n\n%s's servers are experiencing issues. Please try again later.", name, name)
+		if apiError.StatusCode == 0 {
+			return message + "\n\n" + body
+		}
+		return message + fmt.Sprintf("\nStatus: %d", apiError.StatusCode)
+	case errors.Is(apiError, provider.ErrContextLength):
+		return fmt.Sprintf("Error: Prompt too long for the model\n\nShorten the input or choose a model with a larger context window.\n\n%s response: %s", name, body)
+	case errors.Is(apiError, provider.ErrBadRequest) && apiError.StatusCode == 0:
+		return fmt.Sprintf("Error: %s reported an error during the response\n\n%s", name, body)
+	case errors.Is(apiError, provider.ErrBadRequest):
+		return fmt.Sprintf("Error: %s rejected the request (status %d)\n\n%s", name, apiError.StatusCode, body)
+	}
+	return apiError.Error()
+}
+
+func connectionHint(connectionError *provider.ConnectionError) string {
+	if connectionError.Provider == "ollama" {
+		return "Make sure Ollama is running:\n  ollama serve"
+	}
+	return "Please check your network connection and the endpoint in config.yaml."
+}
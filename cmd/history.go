@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/history"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+const historyDateLayout = "2006-01-02"
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List and manage past instruction runs",
+	Long: `Lists the runs recorded by 'gliik run', newest first.
+
+Use --instruction, --since and --until to filter the list. Recording can be
+turned off, or limited to prompt hashes, in the 'history:' section of config.yaml.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := historyFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		records, err := history.List(filter)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No runs found in history.")
+			return nil
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		shown := 0
+		for i := len(records) - 1; i >= 0; i-- {
+			if limit > 0 && shown == limit {
+				break
+			}
+			record := records[i]
+			fmt.Printf("%s  %s  %s v%s  %s/%s  %s  %s\n",
+				record.ID,
+				record.Timestamp.Local().Format("2006-01-02 15:04"),
+				record.Instruction,
+				record.InstructionVersion,
+				record.Provider,
+				record.Model,
+				formatDuration(record.DurationMillis),
+				summarizeOutput(record.Output, 60))
+			shown++
+		}
+
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a recorded run",
+	Long:  `Shows the details, prompt and output of a recorded run. Any unique prefix of the ID is accepted.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		record, err := history.Find(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ID:          %s\n", record.ID)
+		fmt.Printf("Date:        %s\n", record.Timestamp.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("Instruction: %s v%s\n", record.Instruction, record.InstructionVersion)
+		fmt.Printf("Provider:    %s\n", record.Provider)
+		fmt.Printf("Model:       %s\n", record.Model)
+		fmt.Printf("Duration:    %s\n", formatDuration(record.DurationMillis))
+		fmt.Printf("Tokens:      %d in, %d out\n", record.Usage.InputTokens, record.Usage.OutputTokens)
+		if record.Cached {
+			fmt.Println("Cost:        $0 (cached response)")
+		} else if record.CostUSD > 0 {
+			fmt.Printf("Cost:        %s\n", formatCost(record.CostUSD))
+		}
+		fmt.Printf("Prompt hash: %s\n", record.PromptHash)
+		fmt.Println()
+
+		if record.Redacted() {
+			fmt.Println("(prompt redacted)")
+			fmt.Println()
+		} else {
+			fmt.Print(instruction.FormatMessages(record.System, record.Messages))
+		}
+
+		fmt.Println("## output")
+		fmt.Println()
+		fmt.Println(strings.TrimRight(record.Output, "\n"))
+		return nil
+	},
+}
+
+var historyStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show token usage and estimated cost per instruction",
+	Long: `Totals the runs, tokens and estimated cost of the recorded runs per instruction.
+
+Costs are estimated when a run is recorded, from the 'prices:' section of
+config.yaml, so runs of models without a price count as free. Use
+--instruction, --since and --until to filter the runs.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := historyFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		records, err := history.List(filter)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No runs found in history.")
+			return nil
+		}
+
+		var total history.Totals
+		for _, totals := range history.Summarize(records) {
+			fmt.Printf("%s  %d runs (%d cached)  %d in, %d out  %s\n",
+				totals.Instruction,
+				totals.Runs,
+				totals.CachedRuns,
+				totals.Usage.InputTokens,
+				totals.Usage.OutputTokens,
+				formatCost(totals.CostUSD))
+
+			total.Runs += totals.Runs
+			total.CachedRuns += totals.CachedRuns
+			total.Usage.InputTokens += totals.Usage.InputTokens
+			total.Usage.OutputTokens += totals.Usage.OutputTokens
+			total.CostUSD += totals.CostUSD
+		}
+
+		fmt.Printf("Total  %d runs (%d cached)  %d in, %d out  %s\n",
+			total.Runs,
+			total.CachedRuns,
+			total.Usage.InputTokens,
+			total.Usage.OutputTokens,
+			formatCost(total.CostUSD))
+		return nil
+	},
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <id>",
+	Short: "Send a recorded prompt again",
+	Long: `Sends the prompt of a recorded run again with the same provider, model and
+generation options, streams the new reply and records it as a new run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		record, err := history.Find(args[0])
+		if err != nil {
+			return err
+		}
+
+		if record.Redacted() {
+			return fmt.Errorf("run '%s' was recorded with redact_inputs and cannot be re-run", record.ID)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		profile, err := cfg.ResolveProfile(record.Provider)
+		if err != nil {
+			return err
+		}
+
+		httpClient, err := provider.NewHTTPClient(cfg.Transport)
+		if err != nil {
+			return err
+		}
+
+		settings := runSettings{
+			ProfileName: record.Provider,
+			Profile:     profile,
+			Model:       record.Model,
+			Options:     record.Options,
+			MaxAttempts: cfg.Retry.MaxAttempts,
+			HTTPClient:  httpClient,
+		}
+
+		llmProvider, err := newProvider(settings)
+		if err != nil {
+			return err
+		}
+
+		request := provider.Request{
+			Name:     record.Instruction,
+			System:   record.System,
+			Messages: record.Messages,
+			Options:  record.Options,
+		}
+
+		started := time.Now()
+		response, err := completeUntilInterrupted(llmProvider, request, &lineTrackingWriter{writer: os.Stdout})
+		if err != nil {
+			return err
+		}
+
+		recordRun(cfg, record.Instruction, record.InstructionVersion, settings, request, response, time.Since(started))
+		return nil
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old runs from history",
+	Long: `Removes recorded runs older than --before, keeping at most --keep of the
+most recent ones. Use --instruction to prune a single instruction's runs.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		beforeText, _ := cmd.Flags().GetString("before")
+		keep, _ := cmd.Flags().GetInt("keep")
+		instructionName, _ := cmd.Flags().GetString("instruction")
+
+		if beforeText == "" && !cmd.Flags().Changed("keep") {
+			return fmt.Errorf("nothing to prune: use --before <date> or --keep <n>")
+		}
+		if keep < 0 {
+			return fmt.Errorf("invalid --keep value %d: must not be negative", keep)
+		}
+
+		var before time.Time
+		if beforeText != "" {
+			parsed, err := parseHistoryDate(beforeText, "before")
+			if err != nil {
+				return err
+			}
+			before = parsed
+		}
+
+		matching, err := history.List(history.Filter{Instruction: instructionName})
+		if err != nil {
+			return err
+		}
+
+		keepFrom := 0
+		if cmd.Flags().Changed("keep") && len(matching) > keep {
+			keepFrom = len(matching) - keep
+		}
+
+		position := 0
+		removed, err := history.Prune(func(record history.Record) bool {
+			if instructionName != "" && record.Instruction != instructionName {
+				return false
+			}
+			index := position
+			position++
+			if !before.IsZero() && record.Timestamp.Before(before) {
+				return true
+			}
+			return index < keepFrom
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Removed %d runs from history\n", removed)
+		return nil
+	},
+}
+
+// recordRun appends a completed run to the history log unless history is
+// disabled. Failing to record is reported as a warning so that it never
+// hides the reply the user already received.
+func recordRun(cfg *config.Config, instructionName string, instructionVersion string, settings runSettings, request provider.Request, response provider.Response, duration time.Duration) {
+	if cfg.History.Disabled {
+		return
+	}
+
+	record := history.Record{
+		Instruction:        instructionName,
+		InstructionVersion: instructionVersion,
+		Provider:           settings.ProfileName,
+		Model:              settings.Model,
+		PromptHash:         provider.HashPrompt(request.System, request.Messages),
+		Options:            request.Options,
+		Output:             response.Text,
+		DurationMillis:     duration.Milliseconds(),
+		Usage:              response.Usage,
+		Cached:             response.Cached,
+	}
+
+	if cost, priced := estimateCost(cfg, settings.Model, response); priced {
+		record.CostUSD = cost
+	}
+
+	if !cfg.History.RedactInputs {
+		record.System = request.System
+		record.Messages = request.Messages
+	}
+
+	if err := history.Append(&record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func historyFilterFromFlags(cmd *cobra.Command) (history.Filter, error) {
+	filter := history.Filter{}
+	filter.Instruction, _ = cmd.Flags().GetString("instruction")
+
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		parsed, err := parseHistoryDate(since, "since")
+		if err != nil {
+			return history.Filter{}, err
+		}
+		filter.Since = parsed
+	}
+
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		parsed, err := parseHistoryDate(until, "until")
+		if err != nil {
+			return history.Filter{}, err
+		}
+		filter.Until = parsed.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+func parseHistoryDate(value string, flagName string) (time.Time, error) {
+	parsed, err := time.ParseInLocation(historyDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s value '%s': expected a date like 2024-01-31", flagName, value)
+	}
+	return parsed, nil
+}
+
+func formatDuration(millis int64) string {
+	return (time.Duration(millis) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
+
+func summarizeOutput(output string, maxLength int) string {
+	summary := strings.Join(strings.Fields(output), " ")
+	if len([]rune(summary)) > maxLength {
+		summary = string([]rune(summary)[:maxLength-3]) + "..."
+	}
+	return summary
+}
+
+func init() {
+	historyCmd.Flags().String("instruction", "", "Only list runs of this instruction")
+	historyCmd.Flags().String("since", "", "Only list runs on or after this date (YYYY-MM-DD)")
+	historyCmd.Flags().String("until", "", "Only list runs on or before this date (YYYY-MM-DD)")
+	historyCmd.Flags().IntP("limit", "n", 20, "Maximum number of runs to list, 0 for all")
+	historyPruneCmd.Flags().String("before", "", "Remove runs before this date (YYYY-MM-DD)")
+	historyPruneCmd.Flags().Int("keep", 0, "Keep only this many of the most recent runs")
+	historyPruneCmd.Flags().String("instruction", "", "Only prune runs of this instruction")
+	historyStatsCmd.Flags().String("instruction", "", "Only count runs of this instruction")
+	historyStatsCmd.Flags().String("since", "", "Only count runs on or after this date (YYYY-MM-DD)")
+	historyStatsCmd.Flags().String("until", "", "Only count runs on or before this date (YYYY-MM-DD)")
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyStatsCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+	rootCmd.AddCommand(historyCmd)
+}
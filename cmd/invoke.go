@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/cache"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// parseInstructionFlags parses args into a throwaway command holding the flags
+// added by addCommandFlags, and returns it with the values of the instruction
+// variable flags set in args. Instruction commands disable cobra's flag
+// parsing because the variable flags are only known once the instruction is
+// loaded. A variable named like a built-in flag shadows it: the flag sets the
+// variable and the built-in keeps its default, so instructions written before
+// the built-in was added keep working.
+func parseInstructionFlags(variables []instruction.Variable, args []string, addCommandFlags func(*cobra.Command)) (*cobra.Command, map[string]string, error) {
+	tempCmd := &cobra.Command{}
+	addCommandFlags(tempCmd)
+
+	parser := &cobra.Command{}
+	for _, v := range variables {
+		for _, opt := range v.Options {
+			if opt == "input" || parser.Flags().Lookup(opt) != nil {
+				continue
+			}
+			parser.Flags().String(opt, "", fmt.Sprintf("Value for %s", opt))
+		}
+	}
+	parser.Flags().AddFlagSet(tempCmd.Flags())
+
+	if err := parser.ParseFlags(args); err != nil {
+		return nil, nil, err
+	}
+
+	flags := make(map[string]string)
+	for _, v := range variables {
+		for _, opt := range v.Options {
+			if opt != "input" && parser.Flags().Changed(opt) {
+				flags[opt], _ = parser.Flags().GetString(opt)
+			}
+		}
+	}
+
+	return tempCmd, flags, nil
+}
+
+// shadowedFlags returns the variable options of variables named like a flag
+// added by addCommandFlags, sorted by name.
+func shadowedFlags(variables []instruction.Variable, addCommandFlags func(*cobra.Command)) []string {
+	tempCmd := &cobra.Command{}
+	addCommandFlags(tempCmd)
+
+	var shadowed []string
+	for _, v := range variables {
+		for _, opt := range v.Options {
+			if opt != "input" && tempCmd.Flags().Lookup(opt) != nil && !slices.Contains(shadowed, opt) {
+				shadowed = append(shadowed, opt)
+			}
+		}
+	}
+	sort.Strings(shadowed)
+	return shadowed
+}
+
+func readPipedStdin() (string, error) {
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) != 0 {
+		return "", nil
+	}
+
+	stdinBytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return string(stdinBytes), nil
+}
+
+// addProviderFlags adds the flags that select the provider, model, generation
+// options and retries of an instruction run.
+func addProviderFlags(cmd *cobra.Command) {
+	cmd.Flags().String("provider", "", "Provider or profile to use, overriding config and frontmatter")
+	cmd.Flags().String("profile", "", "Profile from config.yaml to use, overriding config and frontmatter")
+	cmd.Flags().String("model", "", "Model to use, overriding config and frontmatter")
+	cmd.Flags().Float64("temperature", 0, "Sampling temperature")
+	cmd.Flags().Int("max-tokens", 0, "Maximum number of tokens to generate")
+	cmd.Flags().Float64("top-p", 0, "Nucleus sampling probability mass")
+	cmd.Flags().StringArray("stop", nil, "Stop sequence (repeatable)")
+	cmd.Flags().Int("seed", 0, "Sampling seed for reproducible output")
+	cmd.Flags().Int("max-attempts", 0, "Attempts per request when the provider fails with a transient error (1 disables retries)")
+}
+
+// addCachedProviderFlags adds the provider flags and the flags that control
+// the response cache.
+func addCachedProviderFlags(cmd *cobra.Command) {
+	addProviderFlags(cmd)
+	cmd.Flags().Bool("cache", false, "Reuse a cached response for an identical request, caching new ones")
+	cmd.Flags().Bool("no-cache", false, "Disable the response cache, even when the instruction enables it")
+	cmd.Flags().Duration("cache-ttl", 0, "Maximum age of a cached response to reuse, such as 24h")
+}
+
+// addChatFlags adds the flags of `gliik chat`: the provider flags, --session
+// and --stats.
+func addChatFlags(cmd *cobra.Command) {
+	addProviderFlags(cmd)
+	addSessionAndStatsFlags(cmd)
+}
+
+func addRunFlags(cmd *cobra.Command) {
+	addCachedProviderFlags(cmd)
+	addSessionAndStatsFlags(cmd)
+	cmd.Flags().Bool("dry-run", false, "Print the provider, model, options and messages without calling the provider")
+	cmd.Flags().Bool("json", false, "With --dry-run, print the request as JSON")
+	cmd.Flags().String("context", "", "Send this file and the files of its [link: path] references as input")
+	cmd.Flags().String("context-selector", "", "With --context, instruction that selects which links to include")
+	addOutputFlags(cmd)
+}
+
+func addSessionAndStatsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("session", "", "Save the conversation as a named session, appending to it if it exists")
+	cmd.Flags().Bool("stats", false, "Print token usage, latency, time to first token and estimated cost to stderr")
+}
+
+// runSettings is the profile, model, generation options, cache and retry
+// settings used for a run after merging the global config, the instruction
+// frontmatter and CLI flags, and the HTTP client built from the config's
+// transport settings.
+type runSettings struct {
+	ProfileName string
+	Profile     config.Profile
+	Model       string
+	Options     provider.GenerationOptions
+	Cache       bool
+	CacheTTL    time.Duration
+	MaxAttempts int
+	HTTPClient  *http.Client
+}
+
+func resolveRunSettings(cfg *config.Config, meta instruction.Meta, cmd *cobra.Command) (runSettings, error) {
+	settings := runSettings{ProfileName: cfg.Provider}
+
+	if settings.ProfileName == "" {
+		settings.ProfileName = config.DefaultProvider
+	}
+
+	if meta.Provider != "" {
+		settings.ProfileName = meta.Provider
+	}
+	instructionProfileName := settings.ProfileName
+
+	if cmd.Flags().Changed("provider") && cmd.Flags().Changed("profile") {
+		return runSettings{}, fmt.Errorf("--provider and --profile cannot be used together")
+	}
+
+	for _, flagName := range []string{"provider", "profile"} {
+		if cmd.Flags().Changed(flagName) {
+			settings.ProfileName, _ = cmd.Flags().GetString(flagName)
+		}
+	}
+
+	profile, err := cfg.ResolveProfile(settings.ProfileName)
+	if err != nil {
+		return runSettings{}, err
+	}
+	settings.Profile = profile
+
+	if meta.Model != "" && settings.ProfileName == instructionProfileName {
+		settings.Model = meta.Model
+	}
+
+	if cmd.Flags().Changed("model") {
+		settings.Model, _ = cmd.Flags().GetString("model")
+	}
+
+	if settings.Model == "" {
+		registration, err := provider.DefaultRegistry.Lookup(profile.Type)
+		if err != nil {
+			return runSettings{}, err
+		}
+		settings.Model = registration.Defaults.Merge(profile.Settings).Model
+	}
+
+	flagOptions, err := generationOptionsFromFlags(cmd)
+	if err != nil {
+		return runSettings{}, err
+	}
+	settings.Options = cfg.Generation.Merge(profile.Generation).Merge(meta.GenerationOptions).Merge(flagOptions)
+
+	if err := resolveCacheSettings(&settings, cfg, meta, cmd); err != nil {
+		return runSettings{}, err
+	}
+
+	settings.HTTPClient, err = provider.NewHTTPClient(cfg.Transport)
+	if err != nil {
+		return runSettings{}, err
+	}
+
+	settings.MaxAttempts = cfg.Retry.MaxAttempts
+	if cmd.Flags().Changed("max-attempts") {
+		settings.MaxAttempts, _ = cmd.Flags().GetInt("max-attempts")
+		if settings.MaxAttempts < 1 {
+			return runSettings{}, fmt.Errorf("--max-attempts must be at least 1")
+		}
+	}
+
+	return settings, nil
+}
+
+// resolveCacheSettings enables the cache from the frontmatter or --cache, with
+// --no-cache taking precedence over both, and reads its TTL from config.yaml
+// or --cache-ttl.
+func resolveCacheSettings(settings *runSettings, cfg *config.Config, meta instruction.Meta, cmd *cobra.Command) error {
+	settings.Cache = meta.Cache
+	if cmd.Flags().Changed("cache") {
+		settings.Cache, _ = cmd.Flags().GetBool("cache")
+	}
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		settings.Cache = false
+	}
+
+	ttl, err := configuredCacheTTL(cfg)
+	if err != nil {
+		return err
+	}
+	settings.CacheTTL = ttl
+
+	if cmd.Flags().Changed("cache-ttl") {
+		settings.CacheTTL, _ = cmd.Flags().GetDuration("cache-ttl")
+	}
+
+	return nil
+}
+
+func configuredCacheTTL(cfg *config.Config) (time.Duration, error) {
+	if cfg.Cache.TTL == "" {
+		return 0, nil
+	}
+
+	ttl, err := time.ParseDuration(cfg.Cache.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache ttl '%s' in config.yaml: use a duration such as 24h or 30m", cfg.Cache.TTL)
+	}
+	return ttl, nil
+}
+
+func generationOptionsFromFlags(cmd *cobra.Command) (provider.GenerationOptions, error) {
+	var options provider.GenerationOptions
+	flags := cmd.Flags()
+
+	if flags.Changed("temperature") {
+		temperature, err := flags.GetFloat64("temperature")
+		if err != nil {
+			return options, err
+		}
+		options.Temperature = &temperature
+	}
+
+	if flags.Changed("max-tokens") {
+		maxTokens, err := flags.GetInt("max-tokens")
+		if err != nil {
+			return options, err
+		}
+		options.MaxTokens = &maxTokens
+	}
+
+	if flags.Changed("top-p") {
+		topP, err := flags.GetFloat64("top-p")
+		if err != nil {
+			return options, err
+		}
+		options.TopP = &topP
+	}
+
+	if flags.Changed("stop") {
+		stop, err := flags.GetStringArray("stop")
+		if err != nil {
+			return options, err
+		}
+		options.Stop = stop
+	}
+
+	if flags.Changed("seed") {
+		seed, err := flags.GetInt("seed")
+		if err != nil {
+			return options, err
+		}
+		options.Seed = &seed
+	}
+
+	return options, nil
+}
+
+// newProvider builds the run's provider, retrying transient errors according
+// to settings.MaxAttempts and reporting each retry on stderr.
+func newProvider(settings runSettings) (provider.LLMProvider, error) {
+	configured := settings.Profile.Settings
+	configured.Model = settings.Model
+
+	llmProvider, err := provider.DefaultRegistry.New(settings.Profile.Type, configured, settings.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.NewRetryingProvider(llmProvider, provider.RetryPolicy{
+		MaxAttempts: settings.MaxAttempts,
+		OnRetry:     printRetry,
+	}), nil
+}
+
+func printRetry(attempt int, delay time.Duration, err error) {
+	reason := strings.SplitN(err.Error(), "\n", 2)[0]
+	fmt.Fprintf(os.Stderr, "Warning: %s; retrying in %s (attempt %d failed)\n", reason, delay.Round(100*time.Millisecond), attempt)
+}
+
+// newCachedProvider builds the run's provider, wrapped in the response cache
+// when the run enables it. With the cache, the provider is only built on the
+// first cache miss, so cached responses are replayed without an API key.
+func newCachedProvider(settings runSettings) (provider.LLMProvider, error) {
+	if !settings.Cache {
+		return newProvider(settings)
+	}
+
+	registration, err := provider.DefaultRegistry.Lookup(settings.Profile.Type)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := registration.Defaults.Merge(settings.Profile.Settings).Endpoint
+
+	newInner := func() (provider.LLMProvider, error) {
+		return newProvider(settings)
+	}
+	return cache.NewProvider(newInner, settings.Profile.Type, endpoint, settings.Model, settings.CacheTTL), nil
+}
+
+// completeUntilInterrupted sends the request and streams the reply to output.
+// Pressing Ctrl-C cancels the request, terminates the partial output with a
+// newline and returns an error carrying ExitInterrupted.
+func completeUntilInterrupted(llmProvider provider.LLMProvider, request provider.Request, output *lineTrackingWriter) (provider.Response, error) {
+	ctx, stopSignalHandling := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignalHandling()
+
+	response, err := llmProvider.Complete(ctx, request, output)
+	if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		output.finishLine()
+		return response, &exitError{code: ExitInterrupted, err: errInterrupted}
+	}
+
+	return response, err
+}
+
+type lineTrackingWriter struct {
+	writer       io.Writer
+	wroteAnyByte bool
+	endsInLine   bool
+}
+
+func (w *lineTrackingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		w.wroteAnyByte = true
+		w.endsInLine = p[n-1] == '\n'
+	}
+	return n, err
+}
+
+func (w *lineTrackingWriter) finishLine() {
+	if w.wroteAnyByte && !w.endsInLine {
+		fmt.Fprintln(w.writer)
+	}
+}
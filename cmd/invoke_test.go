@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+func TestResolveRunSettings_Precedence(t *testing.T) {
+	temperature := func(value float64) *float64 { return &value }
+
+	cfg := &config.Config{
+		Provider:   "ollama",
+		Generation: provider.GenerationOptions{Temperature: temperature(0.1)},
+		Providers: map[string]provider.Settings{
+			"ollama": {Model: "config-model"},
+		},
+		Profiles: map[string]config.Profile{
+			"local": {
+				Type:       "openai",
+				Settings:   provider.Settings{Model: "profile-model"},
+				Generation: provider.GenerationOptions{Temperature: temperature(0.2)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		meta                instruction.Meta
+		args                []string
+		expectedProfile     string
+		expectedModel       string
+		expectedTemperature float64
+	}{
+		{
+			name:                "config",
+			expectedProfile:     "ollama",
+			expectedModel:       "config-model",
+			expectedTemperature: 0.1,
+		},
+		{
+			name:                "profile over config",
+			meta:                instruction.Meta{Provider: "local"},
+			expectedProfile:     "local",
+			expectedModel:       "profile-model",
+			expectedTemperature: 0.2,
+		},
+		{
+			name: "frontmatter over profile",
+			meta: instruction.Meta{
+				Provider:          "local",
+				Model:             "frontmatter-model",
+				GenerationOptions: provider.GenerationOptions{Temperature: temperature(0.3)},
+			},
+			expectedProfile:     "local",
+			expectedModel:       "frontmatter-model",
+			expectedTemperature: 0.3,
+		},
+		{
+			name: "flags over frontmatter",
+			meta: instruction.Meta{
+				Provider:          "local",
+				Model:             "frontmatter-model",
+				GenerationOptions: provider.GenerationOptions{Temperature: temperature(0.3)},
+			},
+			args:                []string{"--model", "flag-model", "--temperature", "0.4"},
+			expectedProfile:     "local",
+			expectedModel:       "flag-model",
+			expectedTemperature: 0.4,
+		},
+		{
+			name:                "provider flag drops frontmatter model",
+			meta:                instruction.Meta{Provider: "local", Model: "frontmatter-model"},
+			args:                []string{"--provider", "ollama"},
+			expectedProfile:     "ollama",
+			expectedModel:       "config-model",
+			expectedTemperature: 0.1,
+		},
+		{
+			name:                "profile flag drops frontmatter model",
+			meta:                instruction.Meta{Model: "frontmatter-model"},
+			args:                []string{"--profile", "local"},
+			expectedProfile:     "local",
+			expectedModel:       "profile-model",
+			expectedTemperature: 0.2,
+		},
+		{
+			name:                "provider flag naming the frontmatter provider keeps its model",
+			meta:                instruction.Meta{Provider: "local", Model: "frontmatter-model"},
+			args:                []string{"--provider", "local"},
+			expectedProfile:     "local",
+			expectedModel:       "frontmatter-model",
+			expectedTemperature: 0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagCmd, _, err := parseInstructionFlags(nil, tt.args, addRunFlags)
+			if err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+
+			settings, err := resolveRunSettings(cfg, tt.meta, flagCmd)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if settings.ProfileName != tt.expectedProfile {
+				t.Errorf("expected profile '%s', got '%s'", tt.expectedProfile, settings.ProfileName)
+			}
+			if settings.Model != tt.expectedModel {
+				t.Errorf("expected model '%s', got '%s'", tt.expectedModel, settings.Model)
+			}
+			if settings.Options.Temperature == nil || *settings.Options.Temperature != tt.expectedTemperature {
+				t.Errorf("expected temperature %v, got %v", tt.expectedTemperature, settings.Options.Temperature)
+			}
+		})
+	}
+}
+
+func TestResolveRunSettings_ProviderAndProfileFlagsConflict(t *testing.T) {
+	flagCmd, _, err := parseInstructionFlags(nil, []string{"--provider", "ollama", "--profile", "local"}, addRunFlags)
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	if _, err := resolveRunSettings(&config.Config{}, instruction.Meta{}, flagCmd); err == nil {
+		t.Error("expected an error when --provider and --profile are both set")
+	}
+}
+
+func TestParseInstructionFlags_VariablesShadowBuiltInFlags(t *testing.T) {
+	variables, err := instruction.ParseVariables("Write {{context}} to {{output}}.")
+	if err != nil {
+		t.Fatalf("failed to parse variables: %v", err)
+	}
+
+	flagCmd, flags, err := parseInstructionFlags(variables, []string{"--output", "report.md", "--context", "notes", "--model", "flag-model"}, addRunFlags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if flags["output"] != "report.md" || flags["context"] != "notes" {
+		t.Errorf("expected the variables to receive the flags, got %v", flags)
+	}
+	if flagCmd.Flags().Changed("output") || flagCmd.Flags().Changed("context") {
+		t.Error("expected the shadowed built-in flags to keep their defaults")
+	}
+	if model, _ := flagCmd.Flags().GetString("model"); model != "flag-model" {
+		t.Errorf("expected the other built-in flags to be parsed, got model '%s'", model)
+	}
+}
+
+func TestShadowedFlags(t *testing.T) {
+	variables, err := instruction.ParseVariables("Write {{model}} to {{output}} with {{input|text}}.")
+	if err != nil {
+		t.Fatalf("failed to parse variables: %v", err)
+	}
+
+	if shadowed := shadowedFlags(variables, addCachedProviderFlags); !reflect.DeepEqual(shadowed, []string{"model"}) {
+		t.Errorf("expected pipeline steps to reserve model only, got %v", shadowed)
+	}
+	if shadowed := shadowedFlags(variables, addRunFlags); !reflect.DeepEqual(shadowed, []string{"model", "output"}) {
+		t.Errorf("expected run to reserve model and output, got %v", shadowed)
+	}
+}
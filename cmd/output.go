@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/atomicfile"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// stdoutPath is the --output value that forces printing to stdout even when
+// the instruction declares an output file.
+const stdoutPath = "-"
+
+// outputTarget is where a run writes its response: stdout when Path is empty,
+// otherwise the file at Path, also echoed to stdout when Tee is set.
+type outputTarget struct {
+	Path   string
+	Append bool
+	Tee    bool
+}
+
+// addOutputFlags adds the flags that send a response to a file.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "Write the response to this file instead of stdout ('-' for stdout); Ctrl-C keeps the part received so far")
+	cmd.Flags().Bool("tee", false, "With an output file, also print the response to stdout")
+	cmd.Flags().Bool("append", false, "With an output file, append the response instead of replacing the file")
+}
+
+// resolveOutputTarget reads --output, --append and --tee, falling back to
+// the instruction's frontmatter output template, expanded with the variable
+// flags, when --output is not set.
+func resolveOutputTarget(cmd *cobra.Command, inst *instruction.Instruction, variables []instruction.Variable, flags map[string]string) (outputTarget, error) {
+	defaultPath := ""
+	if inst.Meta.Output != "" && !cmd.Flags().Changed("output") {
+		path, err := instruction.ExpandOutputPath(inst.Meta.Output, inst.Name, variables, flags, time.Now())
+		if err != nil {
+			return outputTarget{}, err
+		}
+		defaultPath = path
+	}
+
+	return outputTargetFromFlags(cmd, defaultPath)
+}
+
+// outputTargetFromFlags reads --output, --append and --tee, using defaultPath
+// when --output is not set.
+func outputTargetFromFlags(cmd *cobra.Command, defaultPath string) (outputTarget, error) {
+	target := outputTarget{Path: defaultPath}
+	target.Append, _ = cmd.Flags().GetBool("append")
+	target.Tee, _ = cmd.Flags().GetBool("tee")
+
+	if cmd.Flags().Changed("output") {
+		target.Path, _ = cmd.Flags().GetString("output")
+	}
+
+	if target.Path == stdoutPath {
+		target.Path = ""
+	}
+
+	if target.Path == "" && (target.Append || target.Tee) {
+		return outputTarget{}, fmt.Errorf("--append and --tee require an output file: use --output <path>")
+	}
+
+	return target, nil
+}
+
+// completeToTarget streams the response to stdout or to the target file,
+// which is only written once the response completes. When Ctrl-C interrupts
+// the response, the part streamed so far is still written to the file; other
+// failures leave the file untouched.
+func completeToTarget(llmProvider provider.LLMProvider, request provider.Request, target outputTarget) (provider.Response, error) {
+	if target.Path == "" {
+		return completeUntilInterrupted(llmProvider, request, &lineTrackingWriter{writer: os.Stdout})
+	}
+
+	file, err := createOutputFile(target.Path, target.Append)
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	var writer io.Writer = file
+	if target.Tee {
+		writer = io.MultiWriter(file, os.Stdout)
+	}
+
+	output := &lineTrackingWriter{writer: writer}
+	response, err := completeUntilInterrupted(llmProvider, request, output)
+	if errors.Is(err, errInterrupted) && output.wroteAnyByte {
+		if commitErr := file.Commit(); commitErr != nil {
+			return response, commitErr
+		}
+		fmt.Fprintf(os.Stderr, "Partial response saved to %s\n", target.Path)
+		return response, err
+	}
+	if err != nil {
+		file.Discard()
+		return response, err
+	}
+
+	return response, file.Commit()
+}
+
+// createOutputFile starts writing to path, creating its directory first.
+// When appendToExisting is set, the current content of path is copied first
+// so the response is added after it.
+func createOutputFile(path string, appendToExisting bool) (*atomicfile.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := atomicfile.Create(path, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if !appendToExisting {
+		return file, nil
+	}
+
+	existing, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		file.Discard()
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer existing.Close()
+
+	if _, err := io.Copy(file, existing); err != nil {
+		file.Discard()
+		return nil, fmt.Errorf("failed to copy %s: %w", path, err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// interruptedProvider streams a partial reply, then interrupts the process
+// as Ctrl-C would and waits for the request to be cancelled.
+type interruptedProvider struct{}
+
+func (interruptedProvider) Complete(ctx context.Context, request provider.Request, w io.Writer) (provider.Response, error) {
+	io.WriteString(w, "Partial reply")
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return provider.Response{}, err
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		return provider.Response{}, err
+	}
+
+	<-ctx.Done()
+	return provider.Response{Text: "Partial reply"}, ctx.Err()
+}
+
+func TestCompleteToTarget_InterruptKeepsPartialOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.md")
+	if err := os.WriteFile(path, []byte("Previous\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err := completeToTarget(interruptedProvider{}, provider.Request{}, outputTarget{Path: path})
+
+	var exit *exitError
+	if !errors.As(err, &exit) || exit.code != ExitInterrupted {
+		t.Fatalf("expected an interrupted exit error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "Partial reply\n" {
+		t.Errorf("expected the partial reply to be written, got %q", data)
+	}
+}
+
+func TestCompleteToTarget_FailureKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.md")
+	if err := os.WriteFile(path, []byte("Previous\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err := completeToTarget(&provider.MockProvider{ErrorStatus: 500}, provider.Request{}, outputTarget{Path: path})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "Previous\n" {
+		t.Errorf("expected the file to be untouched, got %q", data)
+	}
+}
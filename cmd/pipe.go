@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/pipeline"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+var pipeCmd = &cobra.Command{
+	Use:   "pipe <pipeline.yaml | \"a | b | c\">",
+	Short: "Run instructions in sequence, feeding each output to the next",
+	Long: `Runs a pipeline of instructions in-process. The output of each step is sent to
+the {{input}} of the next one, and the output of the last step is printed.
+Piped stdin is sent to the first step.
+
+A pipeline is either a YAML file or a list of steps separated by '|'. Each step
+is an instruction name followed by the flags 'gliik run' would accept:
+
+  gliik pipe "summarize | translate --lang es"
+  gliik pipe summarize '|' translate --lang "es mx"   # flag values with spaces
+  gliik pipe review.yaml
+
+In a YAML file, steps can also be named with 'as' and map the outputs of
+earlier steps to their variable flags with 'inputs':
+
+  steps:
+    - instruction: summarize
+      as: summary
+      provider: ollama
+    - instruction: translate
+      flags:
+        lang: es
+    - instruction: report
+      inputs:
+        summary: summary
+
+Flags of 'gliik pipe' itself go before the pipeline. Step flags are reserved:
+an instruction with a variable named like one, such as {{model}}, cannot be
+used as a step.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var definition *pipeline.Pipeline
+		var err error
+
+		if len(args) == 1 && isPipelineFile(args[0]) {
+			definition, err = pipeline.Load(args[0])
+		} else {
+			definition, err = pipeline.Parse(args)
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := outputTargetFromFlags(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		return executePipeline(definition, target, verbose)
+	},
+}
+
+func isPipelineFile(arg string) bool {
+	extension := strings.ToLower(filepath.Ext(arg))
+	return extension == ".yaml" || extension == ".yml"
+}
+
+// executePipeline checks every step with checkPipelineStep, then runs the
+// steps in order. Only the last step's output is written to target; with
+// verbose, the name and output of the earlier steps are printed to stderr.
+func executePipeline(definition *pipeline.Pipeline, target outputTarget, verbose bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	input, err := readPipedStdin()
+	if err != nil {
+		return err
+	}
+
+	for i, step := range definition.Steps {
+		if err := checkPipelineStep(step); err != nil {
+			return fmt.Errorf("pipeline step %d (%s): %w", i+1, step.Instruction, err)
+		}
+	}
+
+	outputs := make(map[string]string)
+
+	for i, step := range definition.Steps {
+		lastStep := i == len(definition.Steps)-1
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "→ Step %d/%d: %s\n", i+1, len(definition.Steps), step.Instruction)
+		}
+
+		send := func(llmProvider provider.LLMProvider, request provider.Request) (provider.Response, error) {
+			if lastStep {
+				return completeToTarget(llmProvider, request, target)
+			}
+
+			var writer io.Writer = io.Discard
+			if verbose {
+				writer = os.Stderr
+			}
+			output := &lineTrackingWriter{writer: writer}
+			response, err := completeUntilInterrupted(llmProvider, request, output)
+			output.finishLine()
+			return response, err
+		}
+
+		response, err := runPipelineStep(cfg, step, input, outputs, send)
+		if err != nil {
+			return fmt.Errorf("pipeline step %d (%s) failed: %w", i+1, step.Instruction, err)
+		}
+
+		outputs[step.Name()] = response.Text
+		input = response.Text
+	}
+
+	return nil
+}
+
+// checkPipelineStep checks that the step's instruction exists and has no
+// variable named like a flag of pipeline steps. Such a variable would take
+// the flag, so a step override such as --model would silently become the
+// variable's value.
+func checkPipelineStep(step pipeline.Step) error {
+	inst, err := instruction.Load(step.Instruction)
+	if err != nil {
+		return err
+	}
+
+	variables, err := instruction.ParseVariables(inst.SystemText)
+	if err != nil {
+		return err
+	}
+
+	if shadowed := shadowedFlags(variables, addCachedProviderFlags); len(shadowed) > 0 {
+		return fmt.Errorf("variables named like flags of pipeline steps: %s\n\nThese names are reserved in a pipeline for choosing the provider, model and options of a step. Rename the variables to use the instruction in a pipeline", strings.Join(shadowed, ", "))
+	}
+
+	return nil
+}
+
+// runPipelineStep resolves and renders the step's instruction, sending input
+// to its {{input}} when it accepts one and the outputs of earlier steps to the
+// flags named in step.Inputs, then sends the request with send.
+func runPipelineStep(cfg *config.Config, step pipeline.Step, input string, outputs map[string]string, send func(provider.LLMProvider, provider.Request) (provider.Response, error)) (provider.Response, error) {
+	inst, err := instruction.Load(step.Instruction)
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	variables, err := instruction.ParseVariables(inst.SystemText)
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	cmd, flags, err := parseInstructionFlags(variables, pipelineStepArgs(step), addCachedProviderFlags)
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	resolver := instruction.Resolver{
+		Variables: variables,
+		Flags:     flags,
+		Values:    make(map[string]string),
+	}
+
+	for flagName, stepName := range step.Inputs {
+		if !hasVariableOption(variables, flagName) {
+			return provider.Response{}, fmt.Errorf("input '%s' does not match any variable of instruction '%s'", flagName, inst.Name)
+		}
+		resolver.Values[flagName] = outputs[stepName]
+	}
+
+	if hasVariableOption(variables, "input") {
+		resolver.Stdin = input
+	}
+
+	resolved, err := resolver.Resolve()
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	prompt := instruction.Render(inst, variables, resolved)
+
+	settings, err := resolveRunSettings(cfg, inst.Meta, cmd)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	settings.Options = settings.Options.Merge(step.GenerationOptions)
+
+	llmProvider, err := newCachedProvider(settings)
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	request := provider.Request{
+		Name:     step.Instruction,
+		System:   prompt.System,
+		Messages: prompt.Messages,
+		Options:  settings.Options,
+	}
+
+	started := time.Now()
+	response, err := send(llmProvider, request)
+	if err != nil {
+		return response, err
+	}
+
+	recordRun(cfg, inst.Name, inst.Meta.Version, settings, request, response, time.Since(started))
+	return response, nil
+}
+
+// pipelineStepArgs converts a step into the command-line flags of its
+// instruction: the provider and model overrides, the flags map and the raw
+// arguments of the "a | b" syntax.
+func pipelineStepArgs(step pipeline.Step) []string {
+	var args []string
+	if step.Provider != "" {
+		args = append(args, "--provider", step.Provider)
+	}
+	if step.Model != "" {
+		args = append(args, "--model", step.Model)
+	}
+
+	flagNames := make([]string, 0, len(step.Flags))
+	for flagName := range step.Flags {
+		flagNames = append(flagNames, flagName)
+	}
+	sort.Strings(flagNames)
+
+	for _, flagName := range flagNames {
+		args = append(args, "--"+flagName, step.Flags[flagName])
+	}
+
+	return append(args, step.Args...)
+}
+
+func hasVariableOption(variables []instruction.Variable, option string) bool {
+	for _, variable := range variables {
+		for _, variableOption := range variable.Options {
+			if variableOption == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	addOutputFlags(pipeCmd)
+	pipeCmd.Flags().BoolP("verbose", "v", false, "Print each step and its output to stderr")
+	pipeCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(pipeCmd)
+}
@@ -16,11 +16,13 @@ var rootCmd = &cobra.Command{
 	Long: `Gliik is a CLI tool for managing and executing AI prompts (called "instructions") following UNIX philosophy: composability, minimalism, and clear separation of concerns.
 
 Instructions are stored in directories managed by Gliik (default: ~/.gliik/instructions/) and can contain variables that are resolved from stdin or CLI flags.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, formatError(err))
+		os.Exit(exitCodeFor(err))
 	}
 }
@@ -2,14 +2,14 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/gliik/internal/config"
 	"github.com/yourusername/gliik/internal/instruction"
 	"github.com/yourusername/gliik/internal/provider"
+	"github.com/yourusername/gliik/internal/session"
 )
 
 var runCmd = &cobra.Command{
@@ -35,25 +35,16 @@ var runCmd = &cobra.Command{
 			return err
 		}
 
-		tempCmd := &cobra.Command{}
-
-		for _, v := range variables {
-			for _, opt := range v.Options {
-				if opt != "input" {
-					tempCmd.Flags().String(opt, "", fmt.Sprintf("Value for %s", opt))
-				}
-			}
-		}
-
-		if err := tempCmd.ParseFlags(args[1:]); err != nil {
+		tempCmd, flags, err := parseInstructionFlags(variables, args[1:], addRunFlags)
+		if err != nil {
 			return err
 		}
 
-		return executeInstruction(instructionName, tempCmd)
+		return executeInstruction(instructionName, tempCmd, flags)
 	},
 }
 
-func executeInstruction(name string, cmd *cobra.Command) error {
+func executeInstruction(name string, cmd *cobra.Command, flags map[string]string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -69,27 +60,31 @@ func executeInstruction(name string, cmd *cobra.Command) error {
 		return err
 	}
 
-	stdin := ""
-	stat, _ := os.Stdin.Stat()
-	if (stat.Mode() & os.ModeCharDevice) == 0 {
-		stdinBytes, err := io.ReadAll(os.Stdin)
+	stdin, err := readPipedStdin()
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if asJSON && !dryRun {
+		return fmt.Errorf("--json can only be used with --dry-run")
+	}
+
+	contextPath, _ := cmd.Flags().GetString("context")
+	selectorName, _ := cmd.Flags().GetString("context-selector")
+	if selectorName != "" && contextPath == "" {
+		return fmt.Errorf("--context-selector requires --context <file>")
+	}
+	if selectorName != "" && dryRun {
+		return fmt.Errorf("--context-selector sends a request to the provider and cannot be used with --dry-run")
+	}
+	if contextPath != "" {
+		context, err := buildContext(cfg, contextPath, selectorName, inst.Name, providerFlagArgs(cmd))
 		if err != nil {
-			return fmt.Errorf("failed to read stdin: %w", err)
-		}
-		stdin = string(stdinBytes)
-	}
-
-	flags := make(map[string]string)
-	for _, v := range variables {
-		for _, opt := range v.Options {
-			if opt == "input" {
-				continue
-			}
-			if cmd.Flags().Changed(opt) {
-				value, _ := cmd.Flags().GetString(opt)
-				flags[opt] = value
-			}
+			return err
 		}
+		stdin = joinInputs(context, stdin)
 	}
 
 	resolver := instruction.Resolver{
@@ -103,60 +98,62 @@ func executeInstruction(name string, cmd *cobra.Command) error {
 		return err
 	}
 
-	finalPrompt := inst.SystemText
-	for varRaw, value := range resolved {
-		finalPrompt = strings.ReplaceAll(finalPrompt, varRaw, value)
+	prompt := instruction.Render(inst, variables, resolved)
+
+	settings, err := resolveRunSettings(cfg, inst.Meta, cmd)
+	if err != nil {
+		return err
 	}
 
-	var llmProvider provider.LLMProvider
+	request := provider.Request{
+		System:   prompt.System,
+		Messages: prompt.Messages,
+		Options:  settings.Options,
+	}
 
-	if cfg.Provider == "ollama" {
-		endpoint := cfg.Ollama.Endpoint
-		if endpoint == "" {
-			endpoint = "http://localhost:11434"
-		}
-		model := cfg.Ollama.Model
-		if model == "" {
-			model = "llama3.2"
-		}
-		llmProvider = provider.NewOllamaProvider(endpoint, model)
-	} else if cfg.Provider == "openai" {
-		endpoint := cfg.OpenAI.Endpoint
-		if endpoint == "" {
-			endpoint = "https://api.openai.com/v1"
-		}
-		model := cfg.OpenAI.Model
-		if model == "" {
-			model = "gpt-4o-mini"
-		}
-		llmProvider, err = provider.NewOpenAIProvider(endpoint, model)
-		if err != nil {
-			return err
-		}
-	} else if cfg.Provider == "gemini" {
-		model := cfg.Gemini.Model
-		if model == "" {
-			model = "gemini-2.0-flash"
-		}
-		llmProvider, err = provider.NewGeminiProvider(model)
-		if err != nil {
-			return err
-		}
-	} else {
-		model := cfg.Anthropic.Model
-		if model == "" {
-			model = "claude-sonnet-4-20250514"
-		}
-		llmProvider, err = provider.NewAnthropicProvider(model)
+	sessionName, _ := cmd.Flags().GetString("session")
+	var savedSession *session.Session
+	if sessionName != "" {
+		savedSession, err = openSession(sessionName, inst)
 		if err != nil {
 			return err
 		}
+		request = continueSession(savedSession, prompt, settings.Options)
 	}
+	request.Name = inst.Name
 
-	if err := llmProvider.StreamCompletion("", finalPrompt); err != nil {
+	target, err := resolveOutputTarget(cmd, inst, variables, flags)
+	if err != nil {
 		return err
 	}
 
+	if dryRun {
+		return printDryRun(os.Stdout, inst, settings, request, target, asJSON)
+	}
+
+	llmProvider, err := newCachedProvider(settings)
+	if err != nil {
+		return err
+	}
+	timing := &timedProvider{inner: llmProvider}
+
+	started := time.Now()
+	response, err := completeToTarget(timing, request, target)
+	if err != nil {
+		return err
+	}
+
+	recordRun(cfg, inst.Name, inst.Meta.Version, settings, request, response, time.Since(started))
+
+	if showStats, _ := cmd.Flags().GetBool("stats"); showStats {
+		printRunStats(os.Stderr, cfg, settings, timing, response)
+	}
+
+	if savedSession != nil {
+		messages := append(request.Messages, provider.Message{Role: provider.RoleAssistant, Content: response.Text})
+		return recordSession(savedSession, inst, settings, resolved, request.System, messages)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteInstruction_DryRunWithoutAPIKey(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	gliikHome := filepath.Join(configHome, "gliik")
+	instructionDir := filepath.Join(gliikHome, "instructions", "greet")
+	if err := os.MkdirAll(instructionDir, 0755); err != nil {
+		t.Fatalf("failed to create instruction directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gliikHome, "config.yaml"), []byte("provider: anthropic\nanthropic:\n  api_key_cmd: exit 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	instructionContent := "---\nversion: 0.1.0\ndescription: Greet\ntags: [test]\nlang: en\n---\nSay hello.\n"
+	if err := os.WriteFile(filepath.Join(instructionDir, "instruction.md"), []byte(instructionContent), 0644); err != nil {
+		t.Fatalf("failed to write instruction: %v", err)
+	}
+
+	flagCmd, _, err := parseInstructionFlags(nil, []string{"--dry-run"}, addRunFlags)
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	outputFile, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer outputFile.Close()
+
+	stdout := os.Stdout
+	os.Stdout = outputFile
+	err = executeInstruction("greet", flagCmd, nil)
+	os.Stdout = stdout
+
+	if err != nil {
+		t.Fatalf("expected the dry run to succeed without an API key, got %v", err)
+	}
+
+	output, _ := os.ReadFile(outputFile.Name())
+	if !strings.Contains(string(output), "anthropic") || !strings.Contains(string(output), "Say hello.") {
+		t.Errorf("expected the dry run to print the provider and prompt, got %q", output)
+	}
+}
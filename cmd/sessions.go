@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/gliik/internal/instruction"
+	"github.com/yourusername/gliik/internal/provider"
+	"github.com/yourusername/gliik/internal/session"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage saved conversation sessions",
+	Long:  `Lists, shows, removes and exports the sessions saved with 'gliik run --session' or 'gliik chat --session'.`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := session.List()
+		if err != nil {
+			return err
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No sessions found. Use 'gliik run --session <name>' to create one.")
+			return nil
+		}
+
+		for _, s := range sessions {
+			fmt.Printf("%s - %s v%s, %d messages, %s/%s, updated %s\n",
+				s.Name,
+				s.Instruction,
+				s.InstructionVersion,
+				len(s.Messages),
+				s.Provider,
+				s.Model,
+				s.UpdatedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+		return nil
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a session's details and transcript",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := session.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Session:     %s\n", s.Name)
+		fmt.Printf("Instruction: %s v%s\n", s.Instruction, s.InstructionVersion)
+		fmt.Printf("Provider:    %s\n", s.Provider)
+		fmt.Printf("Model:       %s\n", s.Model)
+		fmt.Printf("Created:     %s\n", s.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("Updated:     %s\n", s.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Println()
+		fmt.Print(instruction.FormatMessages(s.System, s.Messages))
+		return nil
+	},
+}
+
+var sessionsRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a session",
+	Long:    `Deletes a saved session. Requires confirmation unless --force is used.`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if _, err := session.Load(name); err != nil {
+			return err
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !force {
+			fmt.Printf("Delete session '%s'? [y/N]: ", name)
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Operation cancelled")
+				return nil
+			}
+		}
+
+		if err := session.Remove(name); err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Removed session: %s\n", name)
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a session to stdout",
+	Long:  `Writes a session to stdout as JSON, or as markdown in the multi-message instruction format.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := session.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		switch format {
+		case "markdown":
+			fmt.Print(instruction.FormatMessages(s.System, s.Messages))
+		case "json":
+			data, err := json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal session: %w", err)
+			}
+			fmt.Println(string(data))
+		default:
+			return fmt.Errorf("invalid format '%s': must be 'markdown' or 'json'", format)
+		}
+
+		return nil
+	},
+}
+
+// openSession loads the session called name for the given instruction, or
+// starts a new empty one when it does not exist yet.
+func openSession(name string, inst *instruction.Instruction) (*session.Session, error) {
+	existing, err := session.Load(name)
+	if err == nil {
+		if existing.Instruction != inst.Name {
+			return nil, fmt.Errorf("session '%s' belongs to instruction '%s', not '%s'", name, existing.Instruction, inst.Name)
+		}
+		return existing, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return &session.Session{Name: name, Instruction: inst.Name}, nil
+}
+
+// continueSession builds the request for the next turn of a session. A new
+// session starts from the rendered prompt, while an existing one keeps its
+// history and appends the prompt's final message as the new turn.
+func continueSession(s *session.Session, prompt instruction.Prompt, options provider.GenerationOptions) provider.Request {
+	if len(s.Messages) == 0 {
+		return provider.Request{System: prompt.System, Messages: prompt.Messages, Options: options}
+	}
+
+	messages := append([]provider.Message{}, s.Messages...)
+	messages = append(messages, prompt.Messages[len(prompt.Messages)-1])
+	return provider.Request{System: s.System, Messages: messages, Options: options}
+}
+
+// recordSession stores the conversation and the instruction, provider and
+// model that produced its latest turn, then saves the session. The first
+// conversation recorded in a session ends with its first reply.
+func recordSession(s *session.Session, inst *instruction.Instruction, settings runSettings, resolved map[string]string, system string, messages []provider.Message) error {
+	if s.FirstReplyLength == 0 {
+		s.FirstReplyLength = len(messages)
+	}
+	s.InstructionVersion = inst.Meta.Version
+	s.Provider = settings.ProfileName
+	s.Model = settings.Model
+	s.System = system
+	s.Messages = messages
+
+	if len(resolved) > 0 {
+		if s.Variables == nil {
+			s.Variables = make(map[string]string)
+		}
+		for raw, value := range resolved {
+			s.Variables[raw] = value
+		}
+	}
+
+	return s.Save()
+}
+
+func init() {
+	sessionsRemoveCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	sessionsExportCmd.Flags().String("format", "markdown", "Export format: markdown or json")
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsShowCmd)
+	sessionsCmd.AddCommand(sessionsRemoveCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
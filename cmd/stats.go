@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// timedProvider measures the latency and time to first token of the
+// completions of the provider it wraps. Retries are included in both, as they
+// are part of the wait.
+type timedProvider struct {
+	inner      provider.LLMProvider
+	latency    time.Duration
+	firstToken time.Duration
+}
+
+func (p *timedProvider) Complete(ctx context.Context, req provider.Request, w io.Writer) (provider.Response, error) {
+	started := time.Now()
+	p.firstToken = 0
+
+	response, err := p.inner.Complete(ctx, req, &firstWriteWriter{writer: w, onFirstWrite: func() {
+		p.firstToken = time.Since(started)
+	}})
+
+	p.latency = time.Since(started)
+	return response, err
+}
+
+type firstWriteWriter struct {
+	writer       io.Writer
+	onFirstWrite func()
+	wrote        bool
+}
+
+func (w *firstWriteWriter) Write(p []byte) (int, error) {
+	if !w.wrote && len(p) > 0 {
+		w.wrote = true
+		w.onFirstWrite()
+	}
+	return w.writer.Write(p)
+}
+
+// estimateCost returns the cost in US dollars of a response according to the
+// prices in config.yaml, and whether the model has a price. Cached responses
+// cost nothing.
+func estimateCost(cfg *config.Config, model string, response provider.Response) (float64, bool) {
+	if response.Cached {
+		return 0, true
+	}
+	price, exists := cfg.Prices[model]
+	if !exists {
+		return 0, false
+	}
+	return price.Cost(response.Usage), true
+}
+
+// printRunStats writes the token counts, latency, time to first token and
+// estimated cost of a completed run.
+func printRunStats(w io.Writer, cfg *config.Config, settings runSettings, timing *timedProvider, response provider.Response) {
+	fmt.Fprintln(w)
+	if response.Usage == (provider.Usage{}) {
+		fmt.Fprintln(w, "Tokens:      not reported by the provider")
+	} else {
+		fmt.Fprintf(w, "Tokens:      %d in, %d out\n", response.Usage.InputTokens, response.Usage.OutputTokens)
+	}
+
+	fmt.Fprintf(w, "Latency:     %s\n", timing.latency.Round(time.Millisecond))
+	if timing.firstToken > 0 {
+		fmt.Fprintf(w, "First token: %s\n", timing.firstToken.Round(time.Millisecond))
+	}
+
+	cost, priced := estimateCost(cfg, settings.Model, response)
+	switch {
+	case response.Cached:
+		fmt.Fprintln(w, "Cost:        $0 (cached response)")
+	case priced:
+		fmt.Fprintf(w, "Cost:        %s\n", formatCost(cost))
+	default:
+		fmt.Fprintf(w, "Cost:        unknown (add %s to 'prices:' in config.yaml)\n", settings.Model)
+	}
+}
+
+func formatCost(cost float64) string {
+	return fmt.Sprintf("$%.6f", cost)
+}
@@ -0,0 +1,90 @@
+// Package atomicfile writes files so that readers never see a partially
+// written file.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is a temporary file in the same directory as its target. Nothing
+// written to it reaches the target until Commit renames it over the target,
+// so a failed or interrupted write leaves the target untouched.
+type File struct {
+	path string
+	temp *os.File
+}
+
+// Create starts writing a replacement for the file at path. The replacement
+// keeps the permissions of the current file, or gets perm when path does not
+// exist yet. The directory of path must exist.
+func Create(path string, perm os.FileMode) (*File, error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	file := &File{path: path, temp: temp}
+	if err := temp.Chmod(perm); err != nil {
+		file.Discard()
+		return nil, fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// Write appends p to the replacement.
+func (f *File) Write(p []byte) (int, error) {
+	return f.temp.Write(p)
+}
+
+// Commit flushes the replacement to disk and renames it over the target, so
+// that a crash leaves either the old or the new content, never an empty file.
+func (f *File) Commit() error {
+	if err := f.temp.Sync(); err != nil {
+		f.Discard()
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+
+	if err := f.temp.Close(); err != nil {
+		os.Remove(f.temp.Name())
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+
+	if err := os.Rename(f.temp.Name(), f.path); err != nil {
+		os.Remove(f.temp.Name())
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// Discard removes the replacement, leaving the target untouched.
+func (f *File) Discard() {
+	f.temp.Close()
+	os.Remove(f.temp.Name())
+}
+
+// Write replaces the file at path with data. A new file is only readable by
+// its owner.
+func Write(path string, data []byte) error {
+	file, err := Create(path, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Discard()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return file.Commit()
+}
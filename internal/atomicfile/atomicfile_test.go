@@ -0,0 +1,76 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate_CommitReplacesTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	file, err := Create(path, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file.Write([]byte("new"))
+
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("expected the target to be untouched before Commit, got %q", data)
+	}
+
+	if err := file.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new" {
+		t.Errorf("expected 'new', got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("expected the target's permissions to be kept, got %v", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestCreate_DiscardKeepsTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	file, err := Create(path, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file.Write([]byte("partial"))
+	file.Discard()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected Discard not to create the target, got %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWrite_NewFileIsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	if err := Write(path, []byte("{}")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() != "out.txt" {
+			t.Errorf("expected no temporary files, found %s", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Item is a single unit of work of a batch: either a file matched by a glob,
+// whose content is sent as the instruction's input, or a line of a JSONL file
+// holding the instruction's variables.
+type Item struct {
+	// Name identifies the item in progress messages and the report.
+	Name string
+	// InputPath is the file sent as input, empty for JSONL items.
+	InputPath string
+	// Variables maps variable option names to values, empty for glob items.
+	Variables map[string]string
+	// OutputPath is the file the item's response is written to.
+	OutputPath string
+}
+
+// Result is the outcome of processing one item.
+type Result struct {
+	Item     Item
+	Err      error
+	Duration time.Duration
+}
+
+// Report summarizes a batch run. Results are in the order of the items.
+type Report struct {
+	Results   []Result
+	Succeeded int
+	Failed    int
+	Duration  time.Duration
+}
+
+// Options configures Run. Zero values run one item at a time, without rate
+// limiting.
+type Options struct {
+	Concurrency       int
+	RequestsPerMinute int
+	// Progress, when set, is called after each item finishes with the number
+	// of finished items so far. Calls are serialized.
+	Progress func(finished int, total int, result Result)
+}
+
+// Run processes the items with a pool of workers, each item waiting for the
+// rate limit first. Items are not retried here: retries of transient provider
+// errors belong to the provider passed to process. Items not started before
+// ctx is cancelled are reported with the context error.
+func Run(ctx context.Context, items []Item, options Options, process func(ctx context.Context, item Item) error) Report {
+	started := time.Now()
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	limiter := newRateLimiter(options.RequestsPerMinute)
+	results := make([]Result, len(items))
+	indexes := make(chan int)
+
+	var progressMutex sync.Mutex
+	finished := 0
+
+	var workers sync.WaitGroup
+	for range concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				result := processItem(ctx, items[index], limiter, process)
+				results[index] = result
+
+				progressMutex.Lock()
+				finished++
+				if options.Progress != nil {
+					options.Progress(finished, len(items), result)
+				}
+				progressMutex.Unlock()
+			}
+		}()
+	}
+
+	for index := range items {
+		if ctx.Err() != nil {
+			results[index] = Result{Item: items[index], Err: ctx.Err()}
+			continue
+		}
+		select {
+		case indexes <- index:
+		case <-ctx.Done():
+			results[index] = Result{Item: items[index], Err: ctx.Err()}
+		}
+	}
+	close(indexes)
+	workers.Wait()
+
+	report := Report{Results: results, Duration: time.Since(started)}
+	for _, result := range results {
+		if result.Err == nil {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+func processItem(ctx context.Context, item Item, limiter *rateLimiter, process func(ctx context.Context, item Item) error) Result {
+	started := time.Now()
+	result := Result{Item: item}
+
+	result.Err = limiter.wait(ctx)
+	if result.Err == nil {
+		result.Err = process(ctx, item)
+	}
+
+	result.Duration = time.Since(started)
+	return result
+}
+
+// rateLimiter spaces requests evenly so that no more than the configured
+// number start in any minute.
+type rateLimiter struct {
+	interval time.Duration
+	mutex    sync.Mutex
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(requestsPerMinute)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mutex.Unlock()
+
+	return provider.Sleep(ctx, delay)
+}
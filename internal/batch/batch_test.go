@@ -0,0 +1,108 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func makeItems(count int) []Item {
+	items := make([]Item, count)
+	for i := range items {
+		items[i] = Item{Name: fmt.Sprintf("item-%d", i)}
+	}
+	return items
+}
+
+func TestRun_ProcessesEveryItem(t *testing.T) {
+	items := makeItems(20)
+
+	var running, maxRunning int32
+	var seen sync.Map
+	progressCalls := 0
+
+	report := Run(context.Background(), items, Options{
+		Concurrency: 4,
+		Progress: func(finished int, total int, result Result) {
+			progressCalls++
+			if total != 20 {
+				t.Errorf("expected total 20, got %d", total)
+			}
+		},
+	}, func(ctx context.Context, item Item) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		seen.Store(item.Name, true)
+		return nil
+	})
+
+	if report.Succeeded != 20 || report.Failed != 0 {
+		t.Errorf("expected 20 succeeded, got %+v", report)
+	}
+	if progressCalls != 20 {
+		t.Errorf("expected 20 progress calls, got %d", progressCalls)
+	}
+	if maxRunning > 4 {
+		t.Errorf("expected at most 4 concurrent items, got %d", maxRunning)
+	}
+	for i, result := range report.Results {
+		if result.Item.Name != items[i].Name {
+			t.Errorf("expected results in item order, got %s at %d", result.Item.Name, i)
+		}
+	}
+}
+
+func TestRun_ReportsFailures(t *testing.T) {
+	var attempts int32
+
+	report := Run(context.Background(), makeItems(2), Options{}, func(ctx context.Context, item Item) error {
+		atomic.AddInt32(&attempts, 1)
+		if item.Name == "item-1" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if report.Succeeded != 1 || report.Failed != 1 || report.Results[1].Err == nil {
+		t.Errorf("expected one success and one failure, got %+v", report)
+	}
+	if attempts != 2 {
+		t.Errorf("expected each item to be processed once, got %d calls", attempts)
+	}
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	started := time.Now()
+
+	Run(context.Background(), makeItems(3), Options{Concurrency: 3, RequestsPerMinute: 1200}, func(ctx context.Context, item Item) error {
+		return nil
+	})
+
+	if elapsed := time.Since(started); elapsed < 90*time.Millisecond {
+		t.Errorf("expected 3 requests at 1200/min to take at least 100ms, took %s", elapsed)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := Run(ctx, makeItems(3), Options{}, func(ctx context.Context, item Item) error {
+		return nil
+	})
+
+	if report.Failed != 3 {
+		t.Errorf("expected every item to fail after cancellation, got %+v", report)
+	}
+}
@@ -0,0 +1,135 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Glob returns the files matching pattern in lexical order. Besides the
+// wildcards of filepath.Match, a "**" path element matches any number of
+// directories, as in "docs/**/*.md".
+func Glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	base := GlobBase(pattern)
+
+	patternElements := strings.Split(pattern, "/")
+	for _, element := range patternElements {
+		if _, err := path.Match(element, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+		}
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(base), func(walkedPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if matchElements(patternElements, strings.Split(filepath.ToSlash(walkedPath), "/")) {
+			matches = append(matches, walkedPath)
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to search '%s': %w", base, err)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// GlobBase returns the leading directories of pattern that contain no
+// wildcard, or "." when the first element already has one.
+func GlobBase(pattern string) string {
+	elements := strings.Split(filepath.ToSlash(pattern), "/")
+
+	var base []string
+	for _, element := range elements[:len(elements)-1] {
+		if strings.ContainsAny(element, "*?[") {
+			break
+		}
+		base = append(base, element)
+	}
+
+	if len(base) == 0 {
+		return "."
+	}
+	if len(base) == 1 && base[0] == "" {
+		return "/"
+	}
+	return filepath.FromSlash(strings.Join(base, "/"))
+}
+
+func matchElements(pattern []string, name []string) bool {
+	if len(pattern) > 0 && pattern[0] == "." && (len(name) == 0 || name[0] != ".") {
+		pattern = pattern[1:]
+	}
+
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for skipped := 0; skipped <= len(name); skipped++ {
+				if matchElements(rest, name[skipped:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// ReadJSONL reads one variable map per line of a JSONL file, keyed by
+// variable option name. Values must be strings; blank lines are ignored.
+func ReadJSONL(filePath string) ([]map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	var rows []map[string]string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var row map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("%s line %d: expected an object of string values: %w", filePath, lineNumber, err)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	return rows, nil
+}
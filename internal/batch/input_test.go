@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, relativePath := range paths {
+		fullPath := filepath.Join(root, relativePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(relativePath), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "docs/a.md", "docs/b.txt", "docs/guide/c.md", "docs/guide/deep/d.md", "other/e.md")
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"docs/**/*.md", []string{"docs/a.md", "docs/guide/c.md", "docs/guide/deep/d.md"}},
+		{"docs/*.md", []string{"docs/a.md"}},
+		{"**/c.md", []string{"docs/guide/c.md"}},
+		{"docs/guide/**", []string{"docs/guide/c.md", "docs/guide/deep/d.md"}},
+		{"missing/**/*.md", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := Glob(filepath.Join(root, tt.pattern))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var relative []string
+			for _, match := range matches {
+				relativePath, _ := filepath.Rel(root, match)
+				relative = append(relative, filepath.ToSlash(relativePath))
+			}
+
+			if strings.Join(relative, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, relative)
+			}
+		})
+	}
+}
+
+func TestGlobBase(t *testing.T) {
+	tests := map[string]string{
+		"docs/**/*.md":    "docs",
+		"docs/guide/*.md": "docs/guide",
+		"*.md":            ".",
+		"**/*.md":         ".",
+		"/srv/notes/*":    "/srv/notes",
+	}
+
+	for pattern, expected := range tests {
+		if got := GlobBase(pattern); got != filepath.FromSlash(expected) {
+			t.Errorf("GlobBase(%q): expected %q, got %q", pattern, expected, got)
+		}
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	content := `{"text": "first", "lang": "es"}
+
+{"text": "second"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	rows, err := ReadJSONL(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(rows) != 2 || rows[0]["lang"] != "es" || rows[1]["text"] != "second" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestReadJSONL_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	if err := os.WriteFile(path, []byte("{\"text\": \"ok\"}\n{\"count\": 3}\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err := ReadJSONL(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error naming line 2, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gliik/internal/atomicfile"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Entry is a stored completion, kept in one JSON file per cache key.
+type Entry struct {
+	Key          string         `json:"key"`
+	Provider     string         `json:"provider"`
+	Model        string         `json:"model"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Text         string         `json:"text"`
+	FinishReason string         `json:"finish_reason,omitempty"`
+	Usage        provider.Usage `json:"usage"`
+}
+
+// Expired reports whether the entry is older than ttl at now. A zero ttl
+// never expires.
+func (e Entry) Expired(ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(e.CreatedAt) > ttl
+}
+
+// Stats summarizes the contents of the cache directory.
+type Stats struct {
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// GetCacheDir returns the directory holding the cached completions.
+func GetCacheDir() string {
+	return filepath.Join(config.GetGliikHome(), "cache")
+}
+
+func entryFile(key string) string {
+	return filepath.Join(GetCacheDir(), key+".json")
+}
+
+// Key returns the cache key of a request sent to model through the named
+// provider at endpoint. Any difference in the provider, endpoint, model,
+// generation options, system prompt or messages produces a different key.
+func Key(providerName string, endpoint string, model string, request provider.Request) string {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	encoder.Encode(providerName)
+	encoder.Encode(endpoint)
+	encoder.Encode(model)
+	encoder.Encode(request.Options)
+	encoder.Encode(request.System)
+	encoder.Encode(request.Messages)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Lookup returns the entry stored under key. The returned error wraps
+// os.ErrNotExist when there is no entry or it has expired according to ttl.
+func Lookup(key string, ttl time.Duration) (*Entry, error) {
+	entry, err := readEntry(entryFile(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Expired(ttl, time.Now()) {
+		return nil, fmt.Errorf("cache entry %s expired: %w", key, os.ErrNotExist)
+	}
+
+	return entry, nil
+}
+
+// Store saves the entry under its key, replacing any previous one.
+func Store(entry *Entry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(GetCacheDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return atomicfile.Write(entryFile(entry.Key), data)
+}
+
+// GetStats counts the cached entries, treating those older than ttl as
+// expired.
+func GetStats(ttl time.Duration) (Stats, error) {
+	var stats Stats
+	now := time.Now()
+
+	err := forEachEntry(func(path string, size int64, entry *Entry) error {
+		stats.Entries++
+		stats.Bytes += size
+		if entry.Expired(ttl, now) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.CreatedAt.Before(stats.Oldest) {
+			stats.Oldest = entry.CreatedAt
+		}
+		if entry.CreatedAt.After(stats.Newest) {
+			stats.Newest = entry.CreatedAt
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
+// Clear deletes cached entries and returns how many were removed. When
+// expiredOnly is set, only entries older than ttl are deleted.
+func Clear(expiredOnly bool, ttl time.Duration) (int, error) {
+	removed := 0
+	now := time.Now()
+
+	err := forEachEntry(func(path string, size int64, entry *Entry) error {
+		if expiredOnly && !entry.Expired(ttl, now) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+		return nil
+	})
+
+	return removed, err
+}
+
+func forEachEntry(visit func(path string, size int64, entry *Entry) error) error {
+	dirEntries, err := os.ReadDir(GetCacheDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(GetCacheDir(), dirEntry.Name())
+		entry, err := readEntry(path)
+		if err != nil {
+			continue
+		}
+
+		if err := visit(path, info.Size(), entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readEntry(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cache entry not found: %w", os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry %s: %w", filepath.Base(path), err)
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+type countingProvider struct {
+	calls int
+	reply string
+	err   error
+}
+
+func (p *countingProvider) Complete(ctx context.Context, req provider.Request, w io.Writer) (provider.Response, error) {
+	p.calls++
+	if p.err != nil {
+		return provider.Response{}, p.err
+	}
+	io.WriteString(w, p.reply)
+	return provider.Response{Text: p.reply, FinishReason: "stop", Usage: provider.Usage{InputTokens: 7, OutputTokens: 2}}, nil
+}
+
+func innerProvider(inner provider.LLMProvider) func() (provider.LLMProvider, error) {
+	return func() (provider.LLMProvider, error) {
+		return inner, nil
+	}
+}
+
+func testRequest(content string) provider.Request {
+	return provider.Request{System: "Summarize.", Messages: provider.UserMessage(content)}
+}
+
+func TestKey_ChangesWithEveryPart(t *testing.T) {
+	request := testRequest("text")
+	base := Key("openai", "https://api.openai.com/v1", "gpt-4o", request)
+
+	if base != Key("openai", "https://api.openai.com/v1", "gpt-4o", testRequest("text")) {
+		t.Error("expected identical requests to share a key")
+	}
+
+	temperature := 0.2
+	withOptions := testRequest("text")
+	withOptions.Options.Temperature = &temperature
+
+	variants := map[string]string{
+		"provider": Key("ollama", "https://api.openai.com/v1", "gpt-4o", request),
+		"endpoint": Key("openai", "http://localhost:8000/v1", "gpt-4o", request),
+		"model":    Key("openai", "https://api.openai.com/v1", "gpt-4o-mini", request),
+		"options":  Key("openai", "https://api.openai.com/v1", "gpt-4o", withOptions),
+		"system":   Key("openai", "https://api.openai.com/v1", "gpt-4o", provider.Request{System: "Translate.", Messages: request.Messages}),
+		"message":  Key("openai", "https://api.openai.com/v1", "gpt-4o", testRequest("other")),
+	}
+
+	for part, key := range variants {
+		if key == base {
+			t.Errorf("expected a different key when the %s changes", part)
+		}
+	}
+}
+
+func TestProvider_ReplaysCachedResponse(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	inner := &countingProvider{reply: "a summary"}
+	cached := NewProvider(innerProvider(inner), "openai", "https://api.openai.com/v1", "gpt-4o", 0)
+
+	var first, second bytes.Buffer
+	if _, err := cached.Complete(context.Background(), testRequest("text"), &first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	response, err := cached.Complete(context.Background(), testRequest("text"), &second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if inner.calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", inner.calls)
+	}
+	if second.String() != "a summary" || response.Text != "a summary" {
+		t.Errorf("expected replayed output, got %q and %q", second.String(), response.Text)
+	}
+	if response.Usage.OutputTokens != 2 || response.FinishReason != "stop" {
+		t.Errorf("expected stored usage and finish reason, got %+v", response)
+	}
+
+	cached.Complete(context.Background(), testRequest("other"), io.Discard)
+	if inner.calls != 2 {
+		t.Errorf("expected a different request to miss the cache, got %d calls", inner.calls)
+	}
+}
+
+func TestProvider_HitDoesNotBuildProvider(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	warm := NewProvider(innerProvider(&countingProvider{reply: "a summary"}), "openai", "https://api.openai.com/v1", "gpt-4o", 0)
+	if _, err := warm.Complete(context.Background(), testRequest("text"), io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	built := 0
+	cached := NewProvider(func() (provider.LLMProvider, error) {
+		built++
+		return nil, errors.New("ANTHROPIC_API_KEY environment variable is not set")
+	}, "openai", "https://api.openai.com/v1", "gpt-4o", 0)
+
+	response, err := cached.Complete(context.Background(), testRequest("text"), io.Discard)
+	if err != nil || response.Text != "a summary" {
+		t.Fatalf("expected a cache hit, got %q and %v", response.Text, err)
+	}
+	if built != 0 {
+		t.Errorf("expected the provider not to be built on a hit, built %d times", built)
+	}
+
+	if _, err := cached.Complete(context.Background(), testRequest("other"), io.Discard); err == nil {
+		t.Error("expected a miss to report the provider construction error")
+	}
+}
+
+func TestProvider_DoesNotCacheErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	inner := &countingProvider{err: errors.New("boom")}
+	cached := NewProvider(innerProvider(inner), "openai", "https://api.openai.com/v1", "gpt-4o", 0)
+
+	cached.Complete(context.Background(), testRequest("text"), io.Discard)
+	cached.Complete(context.Background(), testRequest("text"), io.Discard)
+
+	if inner.calls != 2 {
+		t.Errorf("expected failed responses not to be cached, got %d calls", inner.calls)
+	}
+}
+
+func TestLookup_Expired(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	entry := &Entry{Key: "abc", Text: "old", CreatedAt: time.Now().Add(-2 * time.Hour)}
+	if err := Store(entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := Lookup("abc", 0); err != nil {
+		t.Errorf("expected entry without TTL, got %v", err)
+	}
+
+	if _, err := Lookup("abc", time.Hour); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected expired entry to be a miss, got %v", err)
+	}
+}
+
+func TestStatsAndClear(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	Store(&Entry{Key: "old", Text: "old", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	Store(&Entry{Key: "new", Text: "new"})
+
+	stats, err := GetStats(time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Entries != 2 || stats.Expired != 1 || stats.Bytes == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	removed, err := Clear(true, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 expired entry removed, got %d", removed)
+	}
+
+	removed, _ = Clear(false, 0)
+	if removed != 1 {
+		t.Errorf("expected remaining entry removed, got %d", removed)
+	}
+}
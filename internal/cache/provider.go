@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Provider is an LLMProvider that answers repeated requests from the on-disk
+// cache. On a hit the stored text is written to the response writer without
+// calling the wrapped provider; on a miss the wrapped provider's successful
+// response is stored for later runs.
+type Provider struct {
+	inner        func() (provider.LLMProvider, error)
+	providerName string
+	endpoint     string
+	model        string
+	ttl          time.Duration
+}
+
+// NewProvider wraps the provider built by newInner, keying its requests by
+// providerName, endpoint and model. newInner is called once, on the first
+// cache miss, so cache hits need neither an API key nor a connection. Entries
+// older than ttl are ignored; a zero ttl keeps them forever.
+func NewProvider(newInner func() (provider.LLMProvider, error), providerName string, endpoint string, model string, ttl time.Duration) *Provider {
+	return &Provider{
+		inner:        sync.OnceValues(newInner),
+		providerName: providerName,
+		endpoint:     endpoint,
+		model:        model,
+		ttl:          ttl,
+	}
+}
+
+// Complete replays a cached response for req when one exists, and otherwise
+// sends req to the wrapped provider and caches its response. A failure to
+// store the response does not fail the completion.
+func (p *Provider) Complete(ctx context.Context, req provider.Request, w io.Writer) (provider.Response, error) {
+	key := Key(p.providerName, p.endpoint, p.model, req)
+
+	if entry, err := Lookup(key, p.ttl); err == nil {
+		if _, err := io.WriteString(w, entry.Text); err != nil {
+			return provider.Response{}, err
+		}
+		return provider.Response{Text: entry.Text, FinishReason: entry.FinishReason, Usage: entry.Usage, Cached: true}, nil
+	}
+
+	inner, err := p.inner()
+	if err != nil {
+		return provider.Response{}, err
+	}
+
+	response, err := inner.Complete(ctx, req, w)
+	if err != nil {
+		return response, err
+	}
+
+	Store(&Entry{
+		Key:          key,
+		Provider:     p.providerName,
+		Model:        p.model,
+		Text:         response.Text,
+		FinishReason: response.FinishReason,
+		Usage:        response.Usage,
+	})
+
+	return response, nil
+}
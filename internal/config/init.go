@@ -3,30 +3,63 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
+	"github.com/yourusername/gliik/internal/provider"
 	"gopkg.in/yaml.v3"
 )
 
-// AnthropicConfig holds configuration for the Anthropic provider.
-type AnthropicConfig struct {
-	Model string `yaml:"model"`
+// DefaultProvider is the provider used when config.yaml does not set one.
+const DefaultProvider = "anthropic"
+
+// Profile is a named provider configuration declared under "profiles:". It
+// lets several configurations of the same provider type coexist, such as
+// OpenAI itself and a local OpenAI-compatible server.
+type Profile struct {
+	// Type is the registered provider name, such as "openai" or "ollama".
+	Type              string `yaml:"type"`
+	provider.Settings `yaml:",inline"`
+	// Generation holds the default sampling parameters for runs using this
+	// profile, applied over the global generation options.
+	Generation provider.GenerationOptions `yaml:"generation,omitempty"`
+}
+
+// HistorySettings controls the run history log written by `gliik run`.
+type HistorySettings struct {
+	// Disabled turns off recording of runs in the history log.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// RedactInputs stores only the hash of the rendered prompt, not its text.
+	// Redacted records cannot be re-run.
+	RedactInputs bool `yaml:"redact_inputs,omitempty"`
+}
+
+// CacheSettings controls the response cache used by `gliik run --cache`.
+type CacheSettings struct {
+	// TTL is how long cached responses stay valid, as a Go duration such as
+	// "24h" or "30m". Empty means cached responses never expire.
+	TTL string `yaml:"ttl,omitempty"`
 }
 
-// OllamaConfig holds configuration for the Ollama provider.
-type OllamaConfig struct {
-	Endpoint string `yaml:"endpoint"`
-	Model    string `yaml:"model"`
+// RetrySettings controls how requests that fail with a transient provider
+// error, such as a rate limit or an overloaded server, are retried.
+type RetrySettings struct {
+	// MaxAttempts is the total number of attempts per request, including the
+	// first one. Zero uses provider.DefaultMaxAttempts and one disables retries.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
 }
 
-// OpenAIConfig holds configuration for the OpenAI provider.
-type OpenAIConfig struct {
-	Endpoint string `yaml:"endpoint"`
-	Model    string `yaml:"model"`
+// ModelPrice is the price of a model in US dollars per million tokens, used
+// to estimate the cost of a run.
+type ModelPrice struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
 }
 
-// GeminiConfig holds configuration for the Gemini provider.
-type GeminiConfig struct {
-	Model string `yaml:"model"`
+// Cost returns the estimated cost in US dollars of a completion that used
+// the given tokens.
+func (p ModelPrice) Cost(usage provider.Usage) float64 {
+	return (float64(usage.InputTokens)*p.Input + float64(usage.OutputTokens)*p.Output) / 1_000_000
 }
 
 // Config represents the Gliik configuration file structure.
@@ -35,22 +68,169 @@ type Config struct {
 	Editor          string `yaml:"editor"`
 	InstructionsDir string `yaml:"instructions_dir,omitempty"`
 	// Provider specifies the LLM provider to use for instruction execution.
-	// Valid values: "anthropic" (default), "ollama", "openai", or "gemini".
-	Provider  string          `yaml:"provider"`
-	Anthropic AnthropicConfig `yaml:"anthropic"`
-	Ollama    OllamaConfig    `yaml:"ollama"`
-	OpenAI    OpenAIConfig    `yaml:"openai"`
-	Gemini    GeminiConfig    `yaml:"gemini"`
+	// Valid values are the name of a profile or one of the names in
+	// provider.DefaultRegistry, such as "anthropic" (default), "ollama",
+	// "openai", or "gemini".
+	Provider string `yaml:"provider"`
+	// Profiles holds the named provider configurations.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	// Generation holds the default sampling parameters for every run. Instruction
+	// frontmatter and `gliik run` flags override them.
+	Generation provider.GenerationOptions `yaml:"generation,omitempty"`
+	// History controls the run history log.
+	History HistorySettings `yaml:"history,omitempty"`
+	// Cache controls the response cache.
+	Cache CacheSettings `yaml:"cache,omitempty"`
+	// Retry controls the retries of transient provider errors.
+	Retry RetrySettings `yaml:"retry,omitempty"`
+	// Transport configures the HTTP client used by every provider: timeouts,
+	// proxy, certificates and extra headers.
+	Transport provider.TransportSettings `yaml:"transport,omitempty"`
+	// Prices holds the price of each model keyed by model name, used to
+	// estimate the cost of runs.
+	Prices map[string]ModelPrice `yaml:"prices,omitempty"`
+	// Providers holds the settings of each provider keyed by provider name,
+	// read from top-level sections such as "anthropic:" or "ollama:".
+	Providers map[string]provider.Settings `yaml:",inline"`
 }
 
-// ValidateProvider checks if the provider value is either "anthropic", "ollama", "openai", or "gemini".
-func (c *Config) ValidateProvider() error {
-	if c.Provider != "anthropic" && c.Provider != "ollama" && c.Provider != "openai" && c.Provider != "gemini" {
-		return fmt.Errorf("invalid provider '%s': must be 'anthropic', 'ollama', 'openai', or 'gemini'", c.Provider)
+// UnmarshalYAML decodes config.yaml, reading every top-level section that is
+// not a Config field as the settings of the provider it names. Sections naming
+// no registered provider, unknown keys in provider sections or profiles and
+// options their provider rejects are errors. Other unknown top-level keys,
+// such as scalars used by other tools, are ignored.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: config.yaml must be a mapping", node.Line)
+	}
+
+	type configFields Config
+	fields := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	providerSections := make(map[string]provider.Settings)
+	knownFields := yamlKeys(reflect.TypeOf(Config{}))
+	settingsKeys := yamlKeys(reflect.TypeOf(provider.Settings{}))
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		switch {
+		case knownFields[key.Value]:
+			fields.Content = append(fields.Content, key, value)
+		case value.Kind == yaml.MappingNode:
+			if _, err := provider.DefaultRegistry.Lookup(key.Value); err != nil {
+				return fmt.Errorf("line %d: %w", key.Line, err)
+			}
+			if err := checkKeys(value, settingsKeys, key.Value); err != nil {
+				return err
+			}
+			var settings provider.Settings
+			if err := value.Decode(&settings); err != nil {
+				return err
+			}
+			if err := provider.DefaultRegistry.Validate(key.Value, settings); err != nil {
+				return fmt.Errorf("line %d: %w", key.Line, err)
+			}
+			providerSections[key.Value] = settings
+		}
+	}
+
+	if err := fields.Decode((*configFields)(c)); err != nil {
+		return err
+	}
+	c.Providers = providerSections
+
+	return checkProfiles(fields, c.Profiles)
+}
+
+// checkProfiles rejects unknown keys in the profiles of fields, and profiles
+// whose type is not a registered provider or whose provider rejects their
+// settings.
+func checkProfiles(fields *yaml.Node, profiles map[string]Profile) error {
+	profileKeys := yamlKeys(reflect.TypeOf(Profile{}))
+
+	for i := 0; i+1 < len(fields.Content); i += 2 {
+		if fields.Content[i].Value != "profiles" {
+			continue
+		}
+		profileNodes := fields.Content[i+1]
+		for j := 0; j+1 < len(profileNodes.Content); j += 2 {
+			name := profileNodes.Content[j]
+			if err := checkKeys(profileNodes.Content[j+1], profileKeys, "profiles."+name.Value); err != nil {
+				return err
+			}
+			profile := profiles[name.Value]
+			if err := provider.DefaultRegistry.Validate(profile.Type, profile.Settings); err != nil {
+				return fmt.Errorf("line %d: profile '%s': %w", name.Line, name.Value, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkKeys(section *yaml.Node, knownKeys map[string]bool, sectionName string) error {
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		key := section.Content[i]
+		if !knownKeys[key.Value] {
+			return fmt.Errorf("line %d: unknown key '%s' in '%s'", key.Line, key.Value, sectionName)
+		}
 	}
 	return nil
 }
 
+// yamlKeys returns the keys a struct of type structType is decoded from,
+// including the keys of its inline struct fields.
+func yamlKeys(structType reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+		if strings.Contains(options, "inline") {
+			if field.Type.Kind() == reflect.Struct {
+				for key := range yamlKeys(field.Type) {
+					keys[key] = true
+				}
+			}
+			continue
+		}
+
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		keys[name] = true
+	}
+	return keys
+}
+
+// ValidateProvider checks that the provider value names a profile or a
+// registered provider.
+func (c *Config) ValidateProvider() error {
+	_, err := c.ResolveProfile(c.Provider)
+	return err
+}
+
+// ResolveProfile returns the profile selected by name. A profile declared under
+// "profiles:" takes precedence; otherwise name must be a registered provider and
+// its top-level section of config.yaml is used.
+func (c *Config) ResolveProfile(name string) (Profile, error) {
+	if profile, exists := c.Profiles[name]; exists {
+		if _, err := provider.DefaultRegistry.Lookup(profile.Type); err != nil {
+			return Profile{}, fmt.Errorf("profile '%s': %w", name, err)
+		}
+		return profile, nil
+	}
+
+	if _, err := provider.DefaultRegistry.Lookup(name); err != nil {
+		return Profile{}, fmt.Errorf("unknown provider or profile '%s'\n\nDeclare it under 'profiles:' in config.yaml or use one of: %s", name, strings.Join(provider.DefaultRegistry.Names(), ", "))
+	}
+
+	return Profile{Type: name, Settings: c.Providers[name]}, nil
+}
+
 func Initialize(instructionsDir string) error {
 	gliikHome := GetGliikHome()
 	configFile := GetConfigFile()
@@ -67,21 +247,16 @@ func Initialize(instructionsDir string) error {
 		DefaultModel:    "claude-sonnet-4-20250514",
 		Editor:          "vim",
 		InstructionsDir: instructionsDir,
-		Provider:        "anthropic",
-		Anthropic: AnthropicConfig{
-			Model: "claude-sonnet-4-20250514",
-		},
-		Ollama: OllamaConfig{
-			Endpoint: "http://localhost:11434",
-			Model:    "llama3.2",
-		},
-		OpenAI: OpenAIConfig{
-			Endpoint: "https://api.openai.com/v1",
-			Model:    "gpt-4o-mini",
-		},
-		Gemini: GeminiConfig{
-			Model: "gemini-2.0-flash",
-		},
+		Provider:        DefaultProvider,
+		Providers:       make(map[string]provider.Settings),
+	}
+
+	for _, name := range provider.DefaultRegistry.Names() {
+		registration, _ := provider.DefaultRegistry.Lookup(name)
+		if registration.Unlisted {
+			continue
+		}
+		defaultConfig.Providers[name] = registration.Defaults
 	}
 
 	data, err := yaml.Marshal(&defaultConfig)
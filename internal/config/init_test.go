@@ -0,0 +1,70 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigUnmarshalYAML_ProviderSections(t *testing.T) {
+	content := `provider: ollama
+instructions_dir: ~/instructions
+theme: dark
+ollama:
+  endpoint: http://localhost:11434
+  model: llama3.2
+mock:
+  options:
+    chunk_size: "4"
+profiles:
+  local:
+    type: openai
+    model: qwen
+`
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Provider != "ollama" || cfg.InstructionsDir != "~/instructions" {
+		t.Errorf("expected config fields to be decoded, got %+v", cfg)
+	}
+	if cfg.Providers["ollama"].Model != "llama3.2" {
+		t.Errorf("expected ollama model 'llama3.2', got '%s'", cfg.Providers["ollama"].Model)
+	}
+	if cfg.Providers["mock"].Options["chunk_size"] != "4" {
+		t.Errorf("expected mock options to be decoded, got %v", cfg.Providers["mock"].Options)
+	}
+	if cfg.Profiles["local"].Model != "qwen" {
+		t.Errorf("expected profile model 'qwen', got '%s'", cfg.Profiles["local"].Model)
+	}
+}
+
+func TestConfigUnmarshalYAML_RejectsUnknownSections(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{"misspelled provider", "antropic:\n  model: claude\n", "invalid provider 'antropic'"},
+		{"misspelled provider key", "anthropic:\n  modle: claude\n", "unknown key 'modle' in 'anthropic'"},
+		{"misspelled profile key", "profiles:\n  local:\n    type: openai\n    modle: qwen\n", "unknown key 'modle' in 'profiles.local'"},
+		{"unknown mock option", "mock:\n  options:\n    fixture: ./fixtures\n", "unknown mock provider option 'fixture'"},
+		{"invalid mock option", "mock:\n  options:\n    latency: soon\n", "invalid mock provider option latency 'soon'"},
+		{"options of a provider without options", "ollama:\n  options:\n    keep_alive: 5m\n", "provider 'ollama' takes no options"},
+		{"invalid profile option", "profiles:\n  slow:\n    type: mock\n    options:\n      chunk_size: -1\n", "profile 'slow': invalid mock provider option chunk_size"},
+		{"unknown profile type", "profiles:\n  local:\n    type: openia\n", "profile 'local': invalid provider 'openia'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := yaml.Unmarshal([]byte(tt.content), &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
@@ -17,11 +20,18 @@ func GetGliikHome() string {
 	return filepath.Join(home, ".config", "gliik")
 }
 
+// GetInstructionsDir returns the instructions directory configured in
+// config.yaml, or the default one under GetGliikHome. A config.yaml that exists
+// but cannot be loaded is reported on stderr before falling back to the
+// default.
 func GetInstructionsDir() string {
 	cfg, err := Load()
 	if err == nil && cfg.InstructionsDir != "" {
 		return expandPath(cfg.InstructionsDir)
 	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the default instructions directory\n", err)
+	}
 
 	return filepath.Join(GetGliikHome(), "instructions")
 }
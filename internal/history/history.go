@@ -0,0 +1,272 @@
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gliik/internal/atomicfile"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Record is a single instruction run stored in the history log. System and
+// Messages are empty when the run was recorded with redacted inputs.
+type Record struct {
+	ID                 string                     `json:"id"`
+	Timestamp          time.Time                  `json:"timestamp"`
+	Instruction        string                     `json:"instruction"`
+	InstructionVersion string                     `json:"instruction_version"`
+	Provider           string                     `json:"provider"`
+	Model              string                     `json:"model"`
+	PromptHash         string                     `json:"prompt_hash"`
+	System             string                     `json:"system,omitempty"`
+	Messages           []provider.Message         `json:"messages,omitempty"`
+	Options            provider.GenerationOptions `json:"options,omitempty"`
+	Output             string                     `json:"output"`
+	DurationMillis     int64                      `json:"duration_ms"`
+	Usage              provider.Usage             `json:"usage"`
+	Cached             bool                       `json:"cached,omitempty"`
+	CostUSD            float64                    `json:"cost_usd,omitempty"`
+}
+
+// Redacted reports whether the record was stored without its inputs.
+func (r Record) Redacted() bool {
+	return len(r.Messages) == 0
+}
+
+// Filter selects records by instruction name and time range. Zero values
+// match every record.
+type Filter struct {
+	Instruction string
+	Since       time.Time
+	Until       time.Time
+}
+
+func (f Filter) matches(record Record) bool {
+	if f.Instruction != "" && record.Instruction != f.Instruction {
+		return false
+	}
+	if !f.Since.IsZero() && record.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !record.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// GetHistoryFile returns the path of the JSONL history log.
+func GetHistoryFile() string {
+	return filepath.Join(config.GetGliikHome(), "history.jsonl")
+}
+
+// Append assigns an ID and timestamp to the record when missing and appends it
+// to the history log.
+func Append(record *Record) error {
+	if record.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(GetHistoryFile()), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	file, err := os.OpenFile(GetHistoryFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the records matching filter, oldest first.
+func List(filter Filter) ([]Record, error) {
+	records, err := readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []Record
+	for _, record := range records {
+		if filter.matches(record) {
+			matching = append(matching, record)
+		}
+	}
+	return matching, nil
+}
+
+// Find returns the record whose ID starts with idPrefix. The prefix must match
+// exactly one record.
+func Find(idPrefix string) (Record, error) {
+	if idPrefix == "" {
+		return Record{}, fmt.Errorf("history ID cannot be empty")
+	}
+
+	records, err := readAll()
+	if err != nil {
+		return Record{}, err
+	}
+
+	var found []Record
+	for _, record := range records {
+		if strings.HasPrefix(record.ID, idPrefix) {
+			found = append(found, record)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Record{}, fmt.Errorf("history record '%s' not found", idPrefix)
+	case 1:
+		return found[0], nil
+	default:
+		return Record{}, fmt.Errorf("history ID '%s' is ambiguous: matches %d records", idPrefix, len(found))
+	}
+}
+
+// Prune removes every record for which remove returns true and returns how
+// many were removed. The history log is rewritten atomically.
+func Prune(remove func(record Record) bool) (int, error) {
+	records, err := readAll()
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []Record
+	for _, record := range records {
+		if !remove(record) {
+			kept = append(kept, record)
+		}
+	}
+
+	removed := len(records) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	var data []byte
+	for _, record := range kept {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal history record: %w", err)
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	if err := atomicfile.Write(GetHistoryFile(), data); err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// Totals sums the runs, tokens and estimated cost of a group of records. The
+// tokens of cached runs are not counted, as no provider was called for them.
+type Totals struct {
+	Instruction string
+	Runs        int
+	CachedRuns  int
+	Usage       provider.Usage
+	CostUSD     float64
+}
+
+// Summarize totals records per instruction, sorted by instruction name.
+func Summarize(records []Record) []Totals {
+	byInstruction := make(map[string]*Totals)
+	var names []string
+
+	for _, record := range records {
+		totals, exists := byInstruction[record.Instruction]
+		if !exists {
+			totals = &Totals{Instruction: record.Instruction}
+			byInstruction[record.Instruction] = totals
+			names = append(names, record.Instruction)
+		}
+
+		totals.Runs++
+		if record.Cached {
+			totals.CachedRuns++
+			continue
+		}
+		totals.Usage.InputTokens += record.Usage.InputTokens
+		totals.Usage.OutputTokens += record.Usage.OutputTokens
+		totals.CostUSD += record.CostUSD
+	}
+
+	sort.Strings(names)
+	summary := make([]Totals, 0, len(names))
+	for _, name := range names {
+		summary = append(summary, *byInstruction[name])
+	}
+	return summary
+}
+
+func readAll() ([]Record, error) {
+	file, err := os.Open(GetHistoryFile())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse history line %d: %w", lineNumber, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return records, nil
+}
+
+func newID() (string, error) {
+	randomBytes := make([]byte, 6)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate history ID: %w", err)
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
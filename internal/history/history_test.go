@@ -0,0 +1,150 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+func appendRecord(t *testing.T, id string, instruction string, timestamp time.Time) {
+	t.Helper()
+	record := &Record{ID: id, Instruction: instruction, Timestamp: timestamp, Output: "output " + id}
+	if err := Append(record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHistory_AppendAndList(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	record := &Record{
+		Instruction: "summarize",
+		Messages:    []provider.Message{{Role: provider.RoleUser, Content: "text"}},
+		Output:      "summary",
+		Usage:       provider.Usage{InputTokens: 10, OutputTokens: 3},
+	}
+	if err := Append(record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.ID == "" || record.Timestamp.IsZero() {
+		t.Error("expected ID and timestamp to be assigned")
+	}
+
+	records, err := List(Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Output != "summary" || records[0].Usage.OutputTokens != 3 || records[0].Redacted() {
+		t.Errorf("unexpected record: %+v", records[0])
+	}
+}
+
+func TestHistory_ListWithoutLog(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	records, err := List(Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestHistory_ListFilters(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	appendRecord(t, "aaa111", "summarize", day.AddDate(0, 0, -2))
+	appendRecord(t, "bbb222", "translate", day)
+	appendRecord(t, "ccc333", "summarize", day.AddDate(0, 0, 2))
+
+	byInstruction, _ := List(Filter{Instruction: "summarize"})
+	if len(byInstruction) != 2 {
+		t.Errorf("expected 2 summarize records, got %d", len(byInstruction))
+	}
+
+	byRange, _ := List(Filter{Since: day.AddDate(0, 0, -1), Until: day.AddDate(0, 0, 1)})
+	if len(byRange) != 1 || byRange[0].ID != "bbb222" {
+		t.Errorf("expected only bbb222 in range, got %+v", byRange)
+	}
+}
+
+func TestHistory_Find(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	now := time.Now()
+	appendRecord(t, "abc123", "summarize", now)
+	appendRecord(t, "abd456", "summarize", now)
+
+	record, err := Find("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record.ID != "abc123" {
+		t.Errorf("expected abc123, got %s", record.ID)
+	}
+
+	if _, err := Find("ab"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguous error, got %v", err)
+	}
+
+	if _, err := Find("zzz"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestHistory_Prune(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	now := time.Now()
+	appendRecord(t, "aaa111", "summarize", now)
+	appendRecord(t, "bbb222", "translate", now)
+	appendRecord(t, "ccc333", "summarize", now)
+
+	removed, err := Prune(func(record Record) bool {
+		return record.Instruction == "summarize"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 removed, got %d", removed)
+	}
+
+	records, _ := List(Filter{})
+	if len(records) != 1 || records[0].ID != "bbb222" {
+		t.Errorf("expected only bbb222 to remain, got %+v", records)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	records := []Record{
+		{Instruction: "summarize", Usage: provider.Usage{InputTokens: 100, OutputTokens: 20}, CostUSD: 0.5},
+		{Instruction: "review", Usage: provider.Usage{InputTokens: 10, OutputTokens: 5}, CostUSD: 0.25},
+		{Instruction: "summarize", Usage: provider.Usage{InputTokens: 100, OutputTokens: 20}, Cached: true},
+	}
+
+	summary := Summarize(records)
+
+	if len(summary) != 2 || summary[0].Instruction != "review" || summary[1].Instruction != "summarize" {
+		t.Fatalf("expected totals for review and summarize, got %+v", summary)
+	}
+
+	summarize := summary[1]
+	if summarize.Runs != 2 || summarize.CachedRuns != 1 {
+		t.Errorf("expected 2 runs with 1 cached, got %d and %d", summarize.Runs, summarize.CachedRuns)
+	}
+	if summarize.Usage.InputTokens != 100 || summarize.Usage.OutputTokens != 20 {
+		t.Errorf("expected the cached run's tokens to be skipped, got %+v", summarize.Usage)
+	}
+	if summarize.CostUSD != 0.5 {
+		t.Errorf("expected cost 0.5, got %v", summarize.CostUSD)
+	}
+}
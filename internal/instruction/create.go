@@ -31,6 +31,15 @@ func ValidateLanguageCode(lang string) error {
 	return nil
 }
 
+// ValidateMode checks the frontmatter mode: empty for the default split into
+// system and user prompts, or ModeSingleMessage.
+func ValidateMode(mode string) error {
+	if mode != "" && mode != ModeSingleMessage {
+		return fmt.Errorf("invalid mode '%s': must be '%s' or omitted for separate system and user messages", mode, ModeSingleMessage)
+	}
+	return nil
+}
+
 func ValidateTags(tags []string) error {
 	if len(tags) == 0 {
 		return fmt.Errorf("at least one tag is required")
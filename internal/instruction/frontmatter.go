@@ -9,7 +9,8 @@ import (
 
 // ParseFrontmatter extracts YAML frontmatter and markdown body from instruction.md content.
 // Returns the parsed metadata, markdown body content, and any error encountered.
-// Frontmatter must be delimited by "---" at the start and end.
+// Frontmatter must be delimited by "---" at the start and end, and its mode,
+// when set, must be a known one.
 func ParseFrontmatter(content string) (Meta, string, error) {
 	delimiter := "---"
 
@@ -46,5 +47,9 @@ func ParseFrontmatter(content string) (Meta, string, error) {
 		return Meta{}, "", fmt.Errorf("failed to parse frontmatter YAML: %w", err)
 	}
 
+	if err := ValidateMode(meta.Mode); err != nil {
+		return Meta{}, "", err
+	}
+
 	return meta, body, nil
 }
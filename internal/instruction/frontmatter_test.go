@@ -231,3 +231,102 @@ Body after newlines`
 		t.Errorf("expected body 'Body after newlines', got '%s'", body)
 	}
 }
+
+func TestParseFrontmatter_ProviderOverrides(t *testing.T) {
+	content := `---
+version: "1.0.0"
+description: "Code review"
+provider: ollama
+model: qwen2.5-coder
+temperature: 0.2
+max_tokens: 2048
+top_p: 0.9
+stop:
+  - "END"
+seed: 42
+cache: true
+---
+Review this code.`
+
+	meta, _, err := ParseFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if meta.Provider != "ollama" {
+		t.Errorf("expected provider 'ollama', got '%s'", meta.Provider)
+	}
+
+	if meta.Model != "qwen2.5-coder" {
+		t.Errorf("expected model 'qwen2.5-coder', got '%s'", meta.Model)
+	}
+
+	if !meta.Cache {
+		t.Error("expected cache to be enabled")
+	}
+
+	if meta.Temperature == nil || *meta.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", meta.Temperature)
+	}
+
+	if meta.MaxTokens == nil || *meta.MaxTokens != 2048 {
+		t.Errorf("expected max_tokens 2048, got %v", meta.MaxTokens)
+	}
+
+	if meta.TopP == nil || *meta.TopP != 0.9 {
+		t.Errorf("expected top_p 0.9, got %v", meta.TopP)
+	}
+
+	if len(meta.Stop) != 1 || meta.Stop[0] != "END" {
+		t.Errorf("expected stop ['END'], got %v", meta.Stop)
+	}
+
+	if meta.Seed == nil || *meta.Seed != 42 {
+		t.Errorf("expected seed 42, got %v", meta.Seed)
+	}
+}
+
+func TestParseFrontmatter_OverridesOmittedWhenUnset(t *testing.T) {
+	content := `---
+version: "1.0.0"
+description: "Minimal"
+---
+Body.`
+
+	meta, _, err := ParseFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if meta.Provider != "" || meta.Model != "" {
+		t.Errorf("expected no provider or model override, got '%s' and '%s'", meta.Provider, meta.Model)
+	}
+
+	if meta.Temperature != nil || meta.MaxTokens != nil {
+		t.Error("expected generation options to be unset")
+	}
+}
+
+func TestParseFrontmatter_Mode(t *testing.T) {
+	for _, mode := range []string{"", ModeSingleMessage} {
+		content := "---\nversion: 0.1.0\nmode: " + mode + "\n---\nBody"
+		meta, _, err := ParseFrontmatter(content)
+		if err != nil {
+			t.Fatalf("expected mode '%s' to be accepted, got %v", mode, err)
+		}
+		if meta.Mode != mode {
+			t.Errorf("expected mode '%s', got '%s'", mode, meta.Mode)
+		}
+	}
+
+	for _, mode := range []string{"single", "singlemessage", "Single-Message"} {
+		_, _, err := ParseFrontmatter("---\nversion: 0.1.0\nmode: " + mode + "\n---\nBody")
+		if err == nil {
+			t.Errorf("expected mode '%s' to be rejected", mode)
+			continue
+		}
+		if !strings.Contains(err.Error(), ModeSingleMessage) {
+			t.Errorf("expected the error to list the valid modes, got %v", err)
+		}
+	}
+}
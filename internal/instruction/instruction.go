@@ -1,15 +1,36 @@
 package instruction
 
+import "github.com/yourusername/gliik/internal/provider"
+
+// Instruction is a loaded instruction.md file. SystemText is the full markdown
+// body, and Messages holds its role-tagged turns when the body uses the
+// multi-message format (see ParseMessages).
 type Instruction struct {
 	Name       string
 	Path       string
 	SystemText string
+	Messages   []provider.Message
 	Meta       Meta
 }
 
+// ModeSingleMessage is the Meta.Mode value that sends the whole rendered
+// instruction as one user message instead of separating system and user prompts.
+const ModeSingleMessage = "single-message"
+
+// Meta is the YAML frontmatter of an instruction.md file. Provider, Model and
+// the generation options are optional and override the global configuration
+// when the instruction runs. Mode selects how the rendered body is split into
+// messages; see Render. Cache enables the response cache for every run, and
+// Output is a default output filename template; see ExpandOutputPath.
 type Meta struct {
-	Version     string   `yaml:"version"`
-	Description string   `yaml:"description"`
-	Tags        []string `yaml:"tags"`
-	Lang        string   `yaml:"lang"`
+	Version                    string   `yaml:"version"`
+	Description                string   `yaml:"description"`
+	Tags                       []string `yaml:"tags"`
+	Lang                       string   `yaml:"lang"`
+	Provider                   string   `yaml:"provider,omitempty"`
+	Model                      string   `yaml:"model,omitempty"`
+	Mode                       string   `yaml:"mode,omitempty"`
+	Cache                      bool     `yaml:"cache,omitempty"`
+	Output                     string   `yaml:"output,omitempty"`
+	provider.GenerationOptions `yaml:",inline"`
 }
@@ -0,0 +1,126 @@
+package instruction
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var linkRegex = regexp.MustCompile(`\[link:\s*([^\]]+?)\s*\]`)
+
+// ContextFile is a markdown file whose [link: path] references point to other
+// files that can be included as context. Relative link paths are resolved
+// against the directory of the context file.
+type ContextFile struct {
+	Path    string
+	Content string
+	Links   []string
+}
+
+// LoadContextFile reads the context file at path and collects its links in
+// order of first appearance.
+func LoadContextFile(path string) (*ContextFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context file: %w", err)
+	}
+
+	return &ContextFile{
+		Path:    path,
+		Content: string(content),
+		Links:   ParseLinks(string(content)),
+	}, nil
+}
+
+// ParseLinks returns the paths of the [link: path] references in content,
+// without duplicates, in order of first appearance.
+func ParseLinks(content string) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	for _, match := range linkRegex.FindAllStringSubmatch(content, -1) {
+		link := match[1]
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// Selection is one path listed by a context selector instruction. Link is
+// set when the selector wrote it as a [link: path] reference rather than as a
+// bare line, which may just be commentary.
+type Selection struct {
+	Path string
+	Link bool
+}
+
+// ParseSelection reads the file paths listed by a context selector
+// instruction, one per line. Markdown bullets and [link: path] wrappers are
+// accepted and blank lines are ignored.
+func ParseSelection(output string) []Selection {
+	var selected []Selection
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*"))
+		line = strings.Trim(line, "`")
+
+		isLink := false
+		if match := linkRegex.FindStringSubmatch(line); match != nil {
+			line = match[1]
+			isLink = true
+		}
+
+		if line != "" && !seen[line] {
+			seen[line] = true
+			selected = append(selected, Selection{Path: line, Link: isLink})
+		}
+	}
+
+	return selected
+}
+
+// ResolveLink returns the path of link, relative to the directory of the
+// context file unless it is absolute.
+func (c *ContextFile) ResolveLink(link string) string {
+	if filepath.IsAbs(link) {
+		return link
+	}
+	return filepath.Join(filepath.Dir(c.Path), link)
+}
+
+// Expand returns the context file content followed by each linked file,
+// delimited by BEGIN and END lines holding its path. Links whose file cannot
+// be read are skipped and returned as missing.
+func (c *ContextFile) Expand(links []string) (string, []string) {
+	var builder strings.Builder
+	builder.WriteString(strings.TrimRight(c.Content, "\n"))
+	builder.WriteString("\n")
+
+	var missing []string
+	for _, link := range links {
+		path := c.ResolveLink(link)
+		if !isFile(path) {
+			missing = append(missing, path)
+			continue
+		}
+
+		content, err := readFile(path)
+		if err != nil {
+			missing = append(missing, path)
+			continue
+		}
+
+		fmt.Fprintf(&builder, "\n--- BEGIN FILE: %s ---\n", link)
+		builder.WriteString(strings.TrimRight(content, "\n"))
+		fmt.Fprintf(&builder, "\n--- END FILE: %s ---\n", link)
+	}
+
+	return builder.String(), missing
+}
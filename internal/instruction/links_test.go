@@ -0,0 +1,82 @@
+package instruction
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	content := `# Project
+See [link: docs/metrics.md] and [link:  data/q1.csv ].
+Again [link: docs/metrics.md], and [not a link].`
+
+	links := ParseLinks(content)
+
+	expected := []string{"docs/metrics.md", "data/q1.csv"}
+	if strings.Join(links, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	output := "Relevant files:\n- docs/metrics.md\n* `data/q1.csv`\n\n[link: notes.md]\ndocs/metrics.md\n"
+
+	selected := ParseSelection(output)
+
+	expected := []Selection{
+		{Path: "Relevant files:"},
+		{Path: "docs/metrics.md"},
+		{Path: "data/q1.csv"},
+		{Path: "notes.md", Link: true},
+	}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("expected %v, got %v", expected, selected)
+	}
+}
+
+func TestContextFile_Expand(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "docs"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "docs", "metrics.md"), []byte("Revenue grew.\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	contextPath := filepath.Join(tempDir, "context.md")
+	if err := os.WriteFile(contextPath, []byte("Context [link: docs/metrics.md] [link: missing.md]\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	contextFile, err := LoadContextFile(contextPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expanded, missing := contextFile.Expand(contextFile.Links)
+
+	expected := "Context [link: docs/metrics.md] [link: missing.md]\n" +
+		"\n--- BEGIN FILE: docs/metrics.md ---\nRevenue grew.\n--- END FILE: docs/metrics.md ---\n"
+	if expanded != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, expanded)
+	}
+
+	if len(missing) != 1 || missing[0] != filepath.Join(tempDir, "missing.md") {
+		t.Errorf("expected missing.md to be reported, got %v", missing)
+	}
+}
+
+func TestContextFile_ResolveLink(t *testing.T) {
+	contextFile := &ContextFile{Path: "/work/project/context.md"}
+
+	if got := contextFile.ResolveLink("notes/a.md"); got != "/work/project/notes/a.md" {
+		t.Errorf("expected relative link resolved against context directory, got %s", got)
+	}
+
+	if got := contextFile.ResolveLink("/etc/hosts"); got != "/etc/hosts" {
+		t.Errorf("expected absolute link unchanged, got %s", got)
+	}
+}
@@ -30,6 +30,11 @@ func Load(name string) (*Instruction, error) {
 		return nil, fmt.Errorf("failed to parse instruction.md: %w", err)
 	}
 
+	messages, err := ParseMessages(systemText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse instruction.md messages: %w", err)
+	}
+
 	if len(meta.Tags) == 0 {
 		fmt.Fprintf(os.Stderr, "Warning: instruction '%s' missing required field 'tags' in frontmatter\n", name)
 	}
@@ -42,6 +47,7 @@ func Load(name string) (*Instruction, error) {
 		Name:       name,
 		Path:       instructionDir,
 		SystemText: systemText,
+		Messages:   messages,
 		Meta:       meta,
 	}, nil
 }
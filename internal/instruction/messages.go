@@ -0,0 +1,93 @@
+package instruction
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// RoleSystem is the role of "## system" sections in a multi-message instruction.
+const RoleSystem = "system"
+
+var roleHeadingRegex = regexp.MustCompile(`(?i)^##\s+(system|user|assistant)\s*$`)
+
+// ParseMessages splits an instruction body into the ordered turns declared by
+// "## system", "## user" and "## assistant" headings. A body is treated as
+// multi-message only when its first non-blank line is one of these headings;
+// otherwise ParseMessages returns nil and the body is a single prompt. Role
+// headings inside fenced code blocks are part of the section content.
+func ParseMessages(body string) ([]provider.Message, error) {
+	lines := strings.Split(body, "\n")
+
+	firstContentLine := 0
+	for firstContentLine < len(lines) && strings.TrimSpace(lines[firstContentLine]) == "" {
+		firstContentLine++
+	}
+	if firstContentLine == len(lines) || !roleHeadingRegex.MatchString(strings.TrimSpace(lines[firstContentLine])) {
+		return nil, nil
+	}
+
+	var messages []provider.Message
+	var sectionLines []string
+	currentRole := ""
+	insideCodeFence := false
+	hasUserMessage := false
+
+	closeSection := func() error {
+		content := strings.TrimSpace(strings.Join(sectionLines, "\n"))
+		if content == "" {
+			return fmt.Errorf("section '## %s' is empty", currentRole)
+		}
+		messages = append(messages, provider.Message{Role: currentRole, Content: content})
+		return nil
+	}
+
+	for _, line := range lines[firstContentLine:] {
+		trimmedLine := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmedLine, "```") {
+			insideCodeFence = !insideCodeFence
+		}
+
+		if !insideCodeFence {
+			if match := roleHeadingRegex.FindStringSubmatch(trimmedLine); match != nil {
+				if currentRole != "" {
+					if err := closeSection(); err != nil {
+						return nil, err
+					}
+				}
+				currentRole = strings.ToLower(match[1])
+				hasUserMessage = hasUserMessage || currentRole == provider.RoleUser
+				sectionLines = nil
+				continue
+			}
+		}
+
+		sectionLines = append(sectionLines, line)
+	}
+
+	if err := closeSection(); err != nil {
+		return nil, err
+	}
+
+	if !hasUserMessage {
+		return nil, fmt.Errorf("multi-message instruction must contain at least one '## user' section")
+	}
+
+	return messages, nil
+}
+
+// FormatMessages writes a conversation in the multi-message format read by
+// ParseMessages, so a saved transcript can be reused as an instruction body.
+func FormatMessages(system string, messages []provider.Message) string {
+	var sections []string
+	if system != "" {
+		sections = append(sections, fmt.Sprintf("## %s\n%s", RoleSystem, system))
+	}
+	for _, message := range messages {
+		sections = append(sections, fmt.Sprintf("## %s\n%s", message.Role, message.Content))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
@@ -0,0 +1,119 @@
+package instruction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+func TestParseMessages_PlainBody(t *testing.T) {
+	body := "# Summarize\n\n## Focus Areas\n\n{{input}}"
+
+	messages, err := ParseMessages(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if messages != nil {
+		t.Errorf("expected nil messages for a plain body, got %+v", messages)
+	}
+}
+
+func TestParseMessages_RoleSections(t *testing.T) {
+	body := `
+## System
+You are a translator.
+
+## user
+Hello
+
+## assistant
+Hola
+
+## user
+{{input}}`
+
+	messages, err := ParseMessages(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []provider.Message{
+		{Role: RoleSystem, Content: "You are a translator."},
+		{Role: provider.RoleUser, Content: "Hello"},
+		{Role: provider.RoleAssistant, Content: "Hola"},
+		{Role: provider.RoleUser, Content: "{{input}}"},
+	}
+
+	if len(messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(messages))
+	}
+
+	for i, message := range expected {
+		if messages[i] != message {
+			t.Errorf("message %d: expected %+v, got %+v", i, message, messages[i])
+		}
+	}
+}
+
+func TestParseMessages_HeadingInsideCodeFence(t *testing.T) {
+	body := "## user\nFormat your answer like this:\n```\n## assistant\n```\n\n## assistant\nOK"
+
+	messages, err := ParseMessages(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	if !strings.Contains(messages[0].Content, "```\n## assistant\n```") {
+		t.Errorf("expected fenced heading to stay in user content, got '%s'", messages[0].Content)
+	}
+}
+
+func TestParseMessages_Errors(t *testing.T) {
+	t.Run("missing user section", func(t *testing.T) {
+		_, err := ParseMessages("## system\nBe brief.")
+		if err == nil || !strings.Contains(err.Error(), "at least one '## user' section") {
+			t.Errorf("expected missing user section error, got %v", err)
+		}
+	})
+
+	t.Run("empty section", func(t *testing.T) {
+		_, err := ParseMessages("## system\n\n## user\nHi")
+		if err == nil || !strings.Contains(err.Error(), "is empty") {
+			t.Errorf("expected empty section error, got %v", err)
+		}
+	})
+}
+
+func TestFormatMessages_RoundTrip(t *testing.T) {
+	messages := []provider.Message{
+		{Role: provider.RoleUser, Content: "Summarize this."},
+		{Role: provider.RoleAssistant, Content: "A short summary."},
+	}
+
+	formatted := FormatMessages("Be concise.", messages)
+
+	parsed, err := ParseMessages(formatted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(parsed) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(parsed))
+	}
+
+	if parsed[0].Role != RoleSystem || parsed[0].Content != "Be concise." {
+		t.Errorf("expected system message 'Be concise.', got %+v", parsed[0])
+	}
+
+	for i, message := range messages {
+		if parsed[i+1] != message {
+			t.Errorf("message %d: expected %+v, got %+v", i, message, parsed[i+1])
+		}
+	}
+}
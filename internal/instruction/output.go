@@ -0,0 +1,65 @@
+package instruction
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var outputPlaceholderRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_-]+)\s*\}\}`)
+
+// ExpandOutputPath fills in the placeholders of an output filename template,
+// such as the frontmatter value "{{name}}.summary.md". flags holds the values
+// passed to the instruction's variable flags, keyed by option name.
+//
+// A placeholder naming a variable option is replaced by the value passed to
+// that flag. Otherwise the following placeholders are available:
+//
+//   - {{instruction}}: the instruction name
+//   - {{date}}: the current date as YYYY-MM-DD
+//   - {{name}}: the file name, without directory or extension, of the first
+//     variable flag naming an existing file, or the instruction name when no
+//     file was passed
+func ExpandOutputPath(template string, instructionName string, variables []Variable, flags map[string]string, now time.Time) (string, error) {
+	var unknown []string
+
+	expanded := outputPlaceholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		key := outputPlaceholderRegex.FindStringSubmatch(placeholder)[1]
+
+		if value, exists := flags[key]; exists {
+			return value
+		}
+
+		switch key {
+		case "instruction":
+			return instructionName
+		case "date":
+			return now.Format("2006-01-02")
+		case "name":
+			return inputFileName(instructionName, variables, flags)
+		}
+
+		unknown = append(unknown, placeholder)
+		return placeholder
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholder %s in output template '%s'\n\nUse {{name}}, {{instruction}}, {{date}} or the name of a flag passed to the instruction", strings.Join(unknown, ", "), template)
+	}
+
+	return expanded, nil
+}
+
+func inputFileName(instructionName string, variables []Variable, flags map[string]string) string {
+	for _, variable := range variables {
+		for _, option := range variable.Options {
+			if value, exists := flags[option]; exists && isFile(value) {
+				base := filepath.Base(value)
+				return strings.TrimSuffix(base, filepath.Ext(base))
+			}
+		}
+	}
+	return instructionName
+}
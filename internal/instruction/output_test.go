@@ -0,0 +1,73 @@
+package instruction
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandOutputPath(t *testing.T) {
+	tempDir := t.TempDir()
+	notesPath := filepath.Join(tempDir, "meeting-notes.md")
+	if err := os.WriteFile(notesPath, []byte("notes"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	variables := []Variable{
+		{Raw: "{{input|text}}", Options: []string{"input", "text"}},
+		{Raw: "{{lang}}", Options: []string{"lang"}},
+	}
+	now := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		flags    map[string]string
+		expected string
+	}{
+		{
+			name:     "name from input file",
+			template: "{{name}}.summary.md",
+			flags:    map[string]string{"text": notesPath},
+			expected: "meeting-notes.summary.md",
+		},
+		{
+			name:     "name falls back to instruction",
+			template: "{{name}}.summary.md",
+			flags:    map[string]string{"text": "inline text"},
+			expected: "summarize.summary.md",
+		},
+		{
+			name:     "instruction, date and flag value",
+			template: "out/{{instruction}}-{{date}}.{{ lang }}.md",
+			flags:    map[string]string{"lang": "es"},
+			expected: "out/summarize-2024-05-17.es.md",
+		},
+		{
+			name:     "no placeholders",
+			template: "report.md",
+			expected: "report.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandOutputPath(tt.template, "summarize", variables, tt.flags, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpandOutputPath_UnknownPlaceholder(t *testing.T) {
+	_, err := ExpandOutputPath("{{author}}.md", "summarize", nil, nil, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "{{author}}") {
+		t.Errorf("expected unknown placeholder error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package instruction
+
+import (
+	"strings"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Prompt is an instruction rendered with its resolved variables, ready to be
+// sent to a provider.
+type Prompt struct {
+	System   string
+	Messages []provider.Message
+}
+
+// Render substitutes the resolved variables into the instruction body.
+//
+// For a multi-message instruction, "## system" sections become the system
+// prompt and the remaining sections are sent as conversation turns, with every
+// variable substituted in place.
+//
+// Otherwise the body becomes the system prompt, while the content of every
+// variable accepting stdin ({{input}} or {{input|...}}) becomes the user message
+// and its placeholder is removed from the system prompt. When the instruction
+// has no input variable, or its mode is ModeSingleMessage, the whole rendered
+// body is sent as the user message with an empty system prompt.
+//
+// Providers reject empty user messages, so they are never sent: when every
+// input is empty, the system prompt is sent as the user message instead, and
+// empty user sections of a multi-message instruction are dropped.
+func Render(inst *Instruction, variables []Variable, resolved map[string]string) Prompt {
+	if inst.Meta.Mode == ModeSingleMessage {
+		return Prompt{Messages: provider.UserMessage(substituteVariables(inst.SystemText, resolved))}
+	}
+
+	if len(inst.Messages) > 0 {
+		return renderMessages(inst.Messages, resolved)
+	}
+
+	inputVariables := inputVariablesOf(variables)
+	if len(inputVariables) == 0 {
+		return Prompt{Messages: provider.UserMessage(substituteVariables(inst.SystemText, resolved))}
+	}
+
+	systemText := inst.SystemText
+	var userParts []string
+	for _, variable := range inputVariables {
+		systemText = strings.ReplaceAll(systemText, variable.Raw, "")
+		if value := resolved[variable.Raw]; value != "" {
+			userParts = append(userParts, value)
+		}
+	}
+
+	prompt := Prompt{
+		System:   strings.TrimRight(substituteVariables(systemText, resolved), " \t\n"),
+		Messages: provider.UserMessage(strings.Join(userParts, "\n\n")),
+	}
+	if len(userParts) == 0 {
+		prompt = systemAsUser(prompt)
+	}
+	return prompt
+}
+
+func renderMessages(messages []provider.Message, resolved map[string]string) Prompt {
+	var prompt Prompt
+	var systemParts []string
+
+	for _, message := range messages {
+		content := substituteVariables(message.Content, resolved)
+		if message.Role == RoleSystem {
+			systemParts = append(systemParts, content)
+			continue
+		}
+		if message.Role == provider.RoleUser && strings.TrimSpace(content) == "" {
+			continue
+		}
+		prompt.Messages = append(prompt.Messages, provider.Message{Role: message.Role, Content: content})
+	}
+
+	prompt.System = strings.Join(systemParts, "\n\n")
+	if len(prompt.Messages) == 0 {
+		prompt = systemAsUser(prompt)
+	}
+	return prompt
+}
+
+// systemAsUser sends the system prompt of a prompt without messages as its
+// user message.
+func systemAsUser(prompt Prompt) Prompt {
+	return Prompt{Messages: provider.UserMessage(prompt.System)}
+}
+
+func inputVariablesOf(variables []Variable) []Variable {
+	var inputVariables []Variable
+	for _, variable := range variables {
+		for _, option := range variable.Options {
+			if option == "input" {
+				inputVariables = append(inputVariables, variable)
+				break
+			}
+		}
+	}
+	return inputVariables
+}
+
+func substituteVariables(text string, resolved map[string]string) string {
+	for raw, value := range resolved {
+		text = strings.ReplaceAll(text, raw, value)
+	}
+	return text
+}
@@ -0,0 +1,155 @@
+package instruction
+
+import (
+	"testing"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+func TestRender_SeparatesInputFromSystemPrompt(t *testing.T) {
+	inst := &Instruction{
+		SystemText: "Summarize the text below in {{lang}}.\n\n{{input|text}}\n",
+	}
+	variables := []Variable{
+		{Raw: "{{lang}}", Options: []string{"lang"}},
+		{Raw: "{{input|text}}", Options: []string{"input", "text"}},
+	}
+	resolved := map[string]string{
+		"{{lang}}":       "Spanish",
+		"{{input|text}}": "The article body.",
+	}
+
+	prompt := Render(inst, variables, resolved)
+
+	expectedSystem := "Summarize the text below in Spanish."
+	if prompt.System != expectedSystem {
+		t.Errorf("expected system '%s', got '%s'", expectedSystem, prompt.System)
+	}
+
+	assertSingleUserMessage(t, prompt, "The article body.")
+}
+
+func TestRender_WithoutInputVariableSendsBodyAsUser(t *testing.T) {
+	inst := &Instruction{
+		SystemText: "Write a haiku about {{topic}}.",
+	}
+	variables := []Variable{
+		{Raw: "{{topic}}", Options: []string{"topic"}},
+	}
+	resolved := map[string]string{"{{topic}}": "autumn"}
+
+	prompt := Render(inst, variables, resolved)
+
+	if prompt.System != "" {
+		t.Errorf("expected empty system prompt, got '%s'", prompt.System)
+	}
+
+	assertSingleUserMessage(t, prompt, "Write a haiku about autumn.")
+}
+
+func TestRender_SingleMessageMode(t *testing.T) {
+	inst := &Instruction{
+		SystemText: "Translate:\n\n{{input}}",
+		Meta:       Meta{Mode: ModeSingleMessage},
+	}
+	variables := []Variable{
+		{Raw: "{{input}}", Options: []string{"input"}},
+	}
+	resolved := map[string]string{"{{input}}": "hola"}
+
+	prompt := Render(inst, variables, resolved)
+
+	if prompt.System != "" {
+		t.Errorf("expected empty system prompt, got '%s'", prompt.System)
+	}
+
+	assertSingleUserMessage(t, prompt, "Translate:\n\nhola")
+}
+
+func TestRender_MultiMessageInstruction(t *testing.T) {
+	body := "## system\nYou classify sentiment.\n\n## user\nI love it\n\n## assistant\npositive\n\n## user\n{{input}}"
+	messages, err := ParseMessages(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inst := &Instruction{SystemText: body, Messages: messages}
+	variables := []Variable{
+		{Raw: "{{input}}", Options: []string{"input"}},
+	}
+	resolved := map[string]string{"{{input}}": "This is awful"}
+
+	prompt := Render(inst, variables, resolved)
+
+	if prompt.System != "You classify sentiment." {
+		t.Errorf("expected system 'You classify sentiment.', got '%s'", prompt.System)
+	}
+
+	expected := []provider.Message{
+		{Role: provider.RoleUser, Content: "I love it"},
+		{Role: provider.RoleAssistant, Content: "positive"},
+		{Role: provider.RoleUser, Content: "This is awful"},
+	}
+
+	if len(prompt.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(prompt.Messages))
+	}
+
+	for i, message := range expected {
+		if prompt.Messages[i] != message {
+			t.Errorf("message %d: expected %+v, got %+v", i, message, prompt.Messages[i])
+		}
+	}
+}
+
+func TestRender_EmptyInputSendsSystemPromptAsUser(t *testing.T) {
+	inst := &Instruction{
+		SystemText: "Summarize the text below.\n\n{{input|text}}\n",
+	}
+	variables := []Variable{
+		{Raw: "{{input|text}}", Options: []string{"input", "text"}},
+	}
+
+	prompt := Render(inst, variables, map[string]string{"{{input|text}}": ""})
+
+	if prompt.System != "" {
+		t.Errorf("expected empty system prompt, got '%s'", prompt.System)
+	}
+
+	assertSingleUserMessage(t, prompt, "Summarize the text below.")
+}
+
+func TestRender_MultiMessageDropsEmptyUserSections(t *testing.T) {
+	body := "## system\nYou classify sentiment.\n\n## user\nI love it\n\n## assistant\npositive\n\n## user\n{{input}}"
+	messages, err := ParseMessages(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inst := &Instruction{SystemText: body, Messages: messages}
+	variables := []Variable{
+		{Raw: "{{input}}", Options: []string{"input"}},
+	}
+
+	prompt := Render(inst, variables, map[string]string{"{{input}}": "  "})
+
+	if len(prompt.Messages) != 2 || prompt.Messages[1].Role != provider.RoleAssistant {
+		t.Errorf("expected the empty user section to be dropped, got %+v", prompt.Messages)
+	}
+}
+
+func assertSingleUserMessage(t *testing.T, prompt Prompt, expectedContent string) {
+	t.Helper()
+
+	if len(prompt.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(prompt.Messages))
+	}
+
+	if prompt.Messages[0].Role != provider.RoleUser {
+		t.Errorf("expected role 'user', got '%s'", prompt.Messages[0].Role)
+	}
+
+	if prompt.Messages[0].Content != expectedContent {
+		t.Errorf("expected user message '%s', got '%s'", expectedContent, prompt.Messages[0].Content)
+	}
+}
@@ -9,6 +9,9 @@ type Resolver struct {
 	Variables []Variable
 	Stdin     string
 	Flags     map[string]string
+	// Values holds option values used as-is, never read as file paths, such
+	// as the outputs of earlier pipeline steps. They take precedence over Flags.
+	Values map[string]string
 }
 
 func (r *Resolver) Resolve() (map[string]string, error) {
@@ -42,6 +45,12 @@ func (r *Resolver) Resolve() (map[string]string, error) {
 				break
 			}
 
+			if literalValue, exists := r.Values[option]; exists {
+				value = literalValue
+				resolvedOption = option
+				break
+			}
+
 			if flagValue, exists := r.Flags[option]; exists {
 				if isFile(flagValue) {
 					content, err := readFile(flagValue)
@@ -189,3 +189,30 @@ func TestResolver_FileResolution(t *testing.T) {
 		t.Errorf("expected 'content from file', got '%s'", resolved["{{text}}"])
 	}
 }
+
+func TestResolver_LiteralValues(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "summary.md")
+	if err := os.WriteFile(filePath, []byte("file content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	variables := []Variable{
+		{Raw: "{{summary}}", Options: []string{"summary"}},
+	}
+
+	resolver := Resolver{
+		Variables: variables,
+		Flags:     map[string]string{"summary": "from flag"},
+		Values:    map[string]string{"summary": filePath},
+	}
+
+	resolved, err := resolver.Resolve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resolved["{{summary}}"] != filePath {
+		t.Errorf("expected literal value '%s', got '%s'", filePath, resolved["{{summary}}"])
+	}
+}
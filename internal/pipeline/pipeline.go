@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/gliik/internal/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// StepSeparator separates the steps of a pipeline written on the command line,
+// as in "summarize | translate --lang es".
+const StepSeparator = "|"
+
+// Pipeline is an ordered list of instructions where the output of each step is
+// sent to the {{input}} of the next one.
+type Pipeline struct {
+	Steps []Step `yaml:"steps"`
+}
+
+// Step runs a single instruction of a pipeline.
+//
+// Provider and Model override the ones the instruction would otherwise use,
+// and the inline generation options override its sampling parameters. Flags
+// sets the instruction's variable flags to literal values or file paths, while
+// Inputs maps variable flags to the outputs of earlier steps, keyed by flag
+// name and referring to steps by their Name. When several earlier steps share
+// a name, the most recent one is used.
+type Step struct {
+	Instruction                string            `yaml:"instruction"`
+	As                         string            `yaml:"as,omitempty"`
+	Provider                   string            `yaml:"provider,omitempty"`
+	Model                      string            `yaml:"model,omitempty"`
+	Flags                      map[string]string `yaml:"flags,omitempty"`
+	Inputs                     map[string]string `yaml:"inputs,omitempty"`
+	provider.GenerationOptions `yaml:",inline"`
+	// Args holds the command-line flags of a step written in the
+	// "a | b | c" syntax, passed to the instruction as `gliik run` would.
+	Args []string `yaml:"-"`
+}
+
+// Name is the name later steps use to refer to this step's output: As when
+// set, otherwise the instruction name.
+func (s Step) Name() string {
+	if s.As != "" {
+		return s.As
+	}
+	return s.Instruction
+}
+
+// Load reads and validates a YAML pipeline definition.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline: %w", err)
+	}
+
+	var pipeline Pipeline
+	if err := yaml.Unmarshal(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline %s: %w", path, err)
+	}
+
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// Parse builds a pipeline from command-line arguments. A single argument is
+// split on "|" and whitespace, as in "summarize | translate --lang es".
+// Several arguments are taken as tokens where a standalone "|" separates the
+// steps, which lets the shell handle quoting of flag values. The first token of
+// each step is the instruction name and the rest are its flags.
+func Parse(args []string) (*Pipeline, error) {
+	tokens := args
+	if len(args) == 1 {
+		tokens = strings.Fields(strings.ReplaceAll(args[0], StepSeparator, " "+StepSeparator+" "))
+	}
+
+	var pipeline Pipeline
+	var current []string
+	addStep := func() error {
+		if len(current) == 0 {
+			return fmt.Errorf("empty step in pipeline: each '%s' must be between two instructions", StepSeparator)
+		}
+		pipeline.Steps = append(pipeline.Steps, Step{Instruction: current[0], Args: current[1:]})
+		current = nil
+		return nil
+	}
+
+	for _, token := range tokens {
+		if token == StepSeparator {
+			if err := addStep(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		current = append(current, token)
+	}
+
+	if err := addStep(); err != nil {
+		return nil, err
+	}
+
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// Validate checks that every step names an instruction and that Inputs only
+// refer to earlier steps. Names given with 'as' must be unique. Unnamed steps
+// running the same instruction share its name, which is only an error when a
+// later step's Inputs refer to it, since the reference would be ambiguous.
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("pipeline has no steps")
+	}
+
+	earlierSteps := make(map[string]int)
+	for i, step := range p.Steps {
+		if step.Instruction == "" {
+			return fmt.Errorf("pipeline step %d: instruction is required", i+1)
+		}
+		if strings.HasPrefix(step.Instruction, "-") {
+			return fmt.Errorf("pipeline step %d: expected an instruction name, got flag '%s'", i+1, step.Instruction)
+		}
+
+		for flagName, stepName := range step.Inputs {
+			if earlierSteps[stepName] == 0 {
+				return fmt.Errorf("pipeline step %d (%s): input '%s' refers to '%s', which is not an earlier step", i+1, step.Instruction, flagName, stepName)
+			}
+			if earlierSteps[stepName] > 1 {
+				return fmt.Errorf("pipeline step %d (%s): input '%s' refers to '%s', which names several earlier steps\n\nGive the step whose output is needed a unique name with 'as'", i+1, step.Instruction, flagName, stepName)
+			}
+			if _, exists := step.Flags[flagName]; exists {
+				return fmt.Errorf("pipeline step %d (%s): '%s' is set in both flags and inputs", i+1, step.Instruction, flagName)
+			}
+		}
+
+		if earlierSteps[step.Name()] > 0 && step.As != "" {
+			return fmt.Errorf("pipeline step %d (%s): name '%s' is already used by an earlier step", i+1, step.Instruction, step.As)
+		}
+		earlierSteps[step.Name()]++
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse_SingleArgument(t *testing.T) {
+	pipeline, err := Parse([]string{"summarize --text notes.md | translate --lang es|title"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pipeline.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(pipeline.Steps))
+	}
+
+	expected := []struct {
+		instruction string
+		args        string
+	}{
+		{"summarize", "--text notes.md"},
+		{"translate", "--lang es"},
+		{"title", ""},
+	}
+
+	for i, want := range expected {
+		step := pipeline.Steps[i]
+		if step.Instruction != want.instruction {
+			t.Errorf("step %d: expected instruction '%s', got '%s'", i+1, want.instruction, step.Instruction)
+		}
+		if strings.Join(step.Args, " ") != want.args {
+			t.Errorf("step %d: expected args '%s', got %q", i+1, want.args, step.Args)
+		}
+	}
+}
+
+func TestParse_SeparateArguments(t *testing.T) {
+	pipeline, err := Parse([]string{"summarize", "--stop", "a|b", "|", "translate", "--lang", "es mx"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pipeline.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(pipeline.Steps))
+	}
+
+	if pipeline.Steps[0].Args[1] != "a|b" {
+		t.Errorf("expected quoted '|' to be kept in a flag value, got %q", pipeline.Steps[0].Args)
+	}
+
+	if pipeline.Steps[1].Args[1] != "es mx" {
+		t.Errorf("expected flag value with spaces to be kept, got %q", pipeline.Steps[1].Args)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"empty step", []string{"summarize | | translate"}, "empty step"},
+		{"trailing separator", []string{"summarize |"}, "empty step"},
+		{"flag before instruction", []string{"--lang es | translate"}, "expected an instruction name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing '%s', got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	content := `steps:
+  - instruction: summarize
+    as: summary
+    provider: ollama
+    model: llama3.2
+    temperature: 0.2
+  - instruction: translate
+    flags:
+      lang: es
+  - instruction: report
+    inputs:
+      summary: summary
+`
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pipeline, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pipeline.Steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(pipeline.Steps))
+	}
+
+	first := pipeline.Steps[0]
+	if first.Name() != "summary" || first.Provider != "ollama" || first.Model != "llama3.2" {
+		t.Errorf("unexpected first step: %+v", first)
+	}
+	if first.Temperature == nil || *first.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", first.Temperature)
+	}
+
+	if pipeline.Steps[1].Name() != "translate" || pipeline.Steps[1].Flags["lang"] != "es" {
+		t.Errorf("unexpected second step: %+v", pipeline.Steps[1])
+	}
+
+	if pipeline.Steps[2].Inputs["summary"] != "summary" {
+		t.Errorf("unexpected third step: %+v", pipeline.Steps[2])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  string
+	}{
+		{"no steps", nil, "no steps"},
+		{"missing instruction", []Step{{As: "x"}}, "instruction is required"},
+		{
+			"input from later step",
+			[]Step{{Instruction: "a", Inputs: map[string]string{"text": "b"}}, {Instruction: "b"}},
+			"not an earlier step",
+		},
+		{
+			"input and flag",
+			[]Step{{Instruction: "a"}, {Instruction: "b", Flags: map[string]string{"text": "x"}, Inputs: map[string]string{"text": "a"}}},
+			"both flags and inputs",
+		},
+		{
+			"duplicate name",
+			[]Step{{Instruction: "a", As: "out"}, {Instruction: "b", As: "out"}},
+			"already used",
+		},
+		{
+			"input from repeated unnamed step",
+			[]Step{{Instruction: "a"}, {Instruction: "a"}, {Instruction: "b", Inputs: map[string]string{"text": "a"}}},
+			"several earlier steps",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := Pipeline{Steps: tt.steps}
+			err := pipeline.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing '%s', got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_RepeatedUnnamedSteps(t *testing.T) {
+	pipeline := Pipeline{Steps: []Step{
+		{Instruction: "translate", Args: []string{"--lang", "es"}},
+		{Instruction: "translate", Args: []string{"--lang", "fr"}},
+		{Instruction: "translate", As: "english", Args: []string{"--lang", "en"}},
+		{Instruction: "report", Inputs: map[string]string{"text": "english"}},
+	}}
+
+	if err := pipeline.Validate(); err != nil {
+		t.Errorf("expected unreferenced repeated steps to be valid, got %v", err)
+	}
+}
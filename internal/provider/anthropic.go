@@ -1,107 +1,229 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"strings"
 )
 
 // AnthropicProvider implements the LLMProvider interface for Anthropic's Claude API.
 type AnthropicProvider struct {
-	APIKey string
-	Model  string
+	APIKey   string
+	Model    string
+	Endpoint string
+	client   *http.Client
 }
 
 var _ LLMProvider = (*AnthropicProvider)(nil)
 
+func init() {
+	DefaultRegistry.Register(Registration{
+		Name: "anthropic",
+		Defaults: Settings{
+			Endpoint:  "https://api.anthropic.com",
+			Model:     "claude-sonnet-4-20250514",
+			APIKeyEnv: "ANTHROPIC_API_KEY",
+		},
+		New: func(settings Settings, client *http.Client) (LLMProvider, error) {
+			return NewAnthropicProvider(settings, client)
+		},
+	})
+}
+
+const anthropicDefaultMaxTokens = 4096
+
 type messageRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []message `json:"messages"`
-	System    string    `json:"system,omitempty"`
+	Model         string    `json:"model"`
+	MaxTokens     int       `json:"max_tokens"`
+	Messages      []Message `json:"messages"`
+	System        string    `json:"system,omitempty"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	TopP          *float64  `json:"top_p,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	Stream        bool      `json:"stream"`
 }
 
-type message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
-type messageResponse struct {
-	Content []contentBlock `json:"content"`
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
 
-type contentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+// AnthropicStreamError is returned when the Anthropic API reports an error
+// event in the middle of a streamed response, such as "overloaded_error".
+type AnthropicStreamError struct {
+	Type    string
+	Message string
+}
+
+func (e *AnthropicStreamError) Error() string {
+	return fmt.Sprintf("Anthropic stream error (%s): %s", e.Type, e.Message)
+}
+
+// Unwrap returns the sentinel error matching the event's error type, such as
+// ErrUnavailable for "overloaded_error", or nil for an unknown type.
+func (e *AnthropicStreamError) Unwrap() error {
+	switch e.Type {
+	case "overloaded_error", "api_error":
+		return ErrUnavailable
+	case "rate_limit_error":
+		return ErrRateLimited
+	case "authentication_error", "permission_error":
+		return ErrAuth
+	case "invalid_request_error", "not_found_error", "request_too_large":
+		if containsAny(e.Message, contextLengthMarkers) {
+			return ErrContextLength
+		}
+		return ErrBadRequest
+	}
+	return nil
 }
 
 // NewAnthropicProvider creates a new AnthropicProvider instance by reading the
-// ANTHROPIC_API_KEY environment variable and using the provided model.
-func NewAnthropicProvider(model string) (*AnthropicProvider, error) {
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+// API key from the source configured in settings (api_key_cmd, api_key_file or
+// the environment variable named in settings.APIKeyEnv) and using the
+// configured endpoint and model. Requests are sent with client, or with
+// http.DefaultClient when it is nil.
+func NewAnthropicProvider(settings Settings, client *http.Client) (*AnthropicProvider, error) {
+	apiKey, err := resolveAPIKey(settings)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is not set")
+		return nil, fmt.Errorf("%s environment variable is not set\n\nTo use Anthropic provider, set your API key:\n  export %s=sk-ant-...\n\nor set api_key_file or api_key_cmd in config.yaml", settings.APIKeyEnv, settings.APIKeyEnv)
 	}
 
 	return &AnthropicProvider{
-		APIKey: apiKey,
-		Model:  model,
+		APIKey:   apiKey,
+		Model:    settings.Model,
+		Endpoint: strings.TrimSuffix(settings.Endpoint, "/"),
+		client:   client,
 	}, nil
 }
 
-// StreamCompletion sends a request to the Anthropic API with the given systemPrompt
-// and userMessage, then streams the response directly to stdout in real-time.
-func (a *AnthropicProvider) StreamCompletion(systemPrompt, userMessage string) error {
+// Complete sends a streaming request to the Anthropic API and writes each text
+// delta to w as the server-sent events arrive. Error events received mid-stream
+// are returned as *AnthropicStreamError.
+func (a *AnthropicProvider) Complete(ctx context.Context, request Request, w io.Writer) (Response, error) {
+	maxTokens := anthropicDefaultMaxTokens
+	if request.Options.MaxTokens != nil {
+		maxTokens = *request.Options.MaxTokens
+	}
+
 	reqBody := messageRequest{
-		Model:     a.Model,
-		MaxTokens: 4096,
-		System:    systemPrompt,
-		Messages: []message{
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
+		Model:         a.Model,
+		MaxTokens:     maxTokens,
+		System:        request.System,
+		Messages:      request.Messages,
+		Temperature:   request.Options.Temperature,
+		TopP:          request.Options.TopP,
+		StopSequences: request.Options.Stop,
+		Stream:        true,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return Response{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", a.Endpoint+"/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return Response{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("x-api-key", a.APIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient(a.client).Do(req)
 	if err != nil {
-		return fmt.Errorf("API request failed: %w", err)
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+		return Response{}, &ConnectionError{Provider: "anthropic", Endpoint: a.Endpoint, Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return Response{}, newAPIError("anthropic", resp)
 	}
 
-	var response messageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	return streamAnthropicEvents(ctx, resp.Body, w)
+}
+
+func streamAnthropicEvents(ctx context.Context, body io.Reader, w io.Writer) (Response, error) {
+	stream := &responseStream{writer: w}
+	var response Response
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		data, isData := sseData(scanner.Text())
+		if !isData || strings.TrimSpace(data) == "" {
+			continue
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			response.Text = stream.text.String()
+			return response, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			response.Usage.InputTokens = event.Message.Usage.InputTokens
+			response.Usage.OutputTokens = event.Message.Usage.OutputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				if err := stream.write(event.Delta.Text); err != nil {
+					response.Text = stream.text.String()
+					return response, err
+				}
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				response.FinishReason = event.Delta.StopReason
+			}
+			if event.Usage.OutputTokens > 0 {
+				response.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			response.Text = stream.text.String()
+			return response, nil
+		case "error":
+			response.Text = stream.text.String()
+			return response, &AnthropicStreamError{Type: event.Error.Type, Message: event.Error.Message}
+		}
 	}
 
-	if len(response.Content) == 0 {
-		return fmt.Errorf("no content in response")
+	response.Text = stream.text.String()
+
+	if err := scanner.Err(); err != nil {
+		return response, stream.streamError(ctx, fmt.Errorf("error reading stream: %w", err))
 	}
 
-	fmt.Print(response.Content[0].Text)
-	return nil
+	return response, stream.streamError(ctx, fmt.Errorf("stream ended before message_stop: %w", io.ErrUnexpectedEOF))
 }
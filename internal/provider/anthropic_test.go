@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCassetteAnthropicProvider(t *testing.T, cassette string) *AnthropicProvider {
+	server := newCassetteServer(t, cassette, "https://api.anthropic.com")
+	return &AnthropicProvider{
+		APIKey:   cassetteAPIKey(t, cassette, "ANTHROPIC_API_KEY"),
+		Model:    "claude-sonnet-4-20250514",
+		Endpoint: server.URL,
+	}
+}
+
+func TestAnthropicProvider_Cassettes(t *testing.T) {
+	tests := []cassetteTest{
+		{
+			cassette:       "anthropic_stream",
+			expectedText:   "Hello! How can I help?",
+			expectedFinish: "end_turn",
+			expectedUsage:  Usage{InputTokens: 12, OutputTokens: 9},
+		},
+		{
+			cassette:      "anthropic_truncated",
+			expectedText:  "Hello!",
+			expectedUsage: Usage{InputTokens: 12, OutputTokens: 1},
+			expectedErr:   io.ErrUnexpectedEOF,
+		},
+		{cassette: "anthropic_unauthorized", expectedErr: ErrAuth},
+		{cassette: "anthropic_overloaded", expectedErr: ErrUnavailable, expectedRetryAfter: 3 * time.Second},
+		{cassette: "anthropic_prompt_too_long", expectedErr: ErrContextLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			checkCassette(t, newCassetteAnthropicProvider(t, tt.cassette), tt)
+		})
+	}
+}
+
+func TestAnthropicProvider_StreamErrorEvent(t *testing.T) {
+	anthropic := newCassetteAnthropicProvider(t, "anthropic_stream_error")
+
+	var output strings.Builder
+	response, err := anthropic.Complete(t.Context(), cassettePrompt(), &output)
+
+	var streamError *AnthropicStreamError
+	if !errors.As(err, &streamError) || streamError.Type != "overloaded_error" {
+		t.Fatalf("expected an overloaded_error stream error, got %v", err)
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected the stream error to match ErrUnavailable, got %v", err)
+	}
+	if response.Text != "Hel" || output.String() != "Hel" {
+		t.Errorf("expected the partial text to be kept, got %q", response.Text)
+	}
+}
+
+func TestAnthropicStreamError_Unwrap(t *testing.T) {
+	tests := []struct {
+		errorType string
+		message   string
+		expected  error
+	}{
+		{"overloaded_error", "Overloaded", ErrUnavailable},
+		{"api_error", "Internal server error", ErrUnavailable},
+		{"rate_limit_error", "Too many requests", ErrRateLimited},
+		{"authentication_error", "invalid x-api-key", ErrAuth},
+		{"invalid_request_error", "prompt is too long: 210000 tokens > 200000 maximum", ErrContextLength},
+		{"invalid_request_error", "max_tokens: must be positive", ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.errorType, func(t *testing.T) {
+			err := &AnthropicStreamError{Type: tt.errorType, Message: tt.message}
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err.Unwrap())
+			}
+		})
+	}
+
+	if (&AnthropicStreamError{Type: "unknown_error"}).Unwrap() != nil {
+		t.Error("expected an unknown error type to match no sentinel error")
+	}
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// apiKeyCache holds the keys read from files and commands, so that each is
+// read or run at most once per process.
+var apiKeyCache = struct {
+	sync.Mutex
+	keys map[string]string
+}{keys: make(map[string]string)}
+
+// resolveAPIKey returns the API key configured in settings: the trimmed
+// output of APIKeyCmd, the trimmed content of APIKeyFile, or the value of the
+// APIKeyEnv environment variable, in that order of precedence. An unset
+// environment variable returns an empty key, so callers can explain how to set
+// it. Errors never include the key or the command's output.
+func resolveAPIKey(settings Settings) (string, error) {
+	if settings.APIKeyCmd != "" && settings.APIKeyFile != "" {
+		return "", errors.New("api_key_cmd and api_key_file cannot be used together")
+	}
+
+	switch {
+	case settings.APIKeyCmd != "":
+		return cachedAPIKey("cmd:"+settings.APIKeyCmd, func() (string, error) {
+			return readAPIKeyCommand(settings.APIKeyCmd)
+		})
+	case settings.APIKeyFile != "":
+		return cachedAPIKey("file:"+settings.APIKeyFile, func() (string, error) {
+			return readAPIKeyFile(settings.APIKeyFile)
+		})
+	case settings.APIKeyEnv != "":
+		return strings.TrimSpace(os.Getenv(settings.APIKeyEnv)), nil
+	}
+	return "", nil
+}
+
+func cachedAPIKey(source string, read func() (string, error)) (string, error) {
+	apiKeyCache.Lock()
+	defer apiKeyCache.Unlock()
+
+	if key, exists := apiKeyCache.keys[source]; exists {
+		return key, nil
+	}
+
+	key, err := read()
+	if err != nil {
+		return "", err
+	}
+	apiKeyCache.keys[source] = key
+	return key, nil
+}
+
+func readAPIKeyFile(path string) (string, error) {
+	if rest, found := strings.CutPrefix(path, "~/"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read api_key_file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return "", fmt.Errorf("api_key_file %s is empty", path)
+	}
+	return key, nil
+}
+
+// readAPIKeyCommand runs command with the shell and returns its trimmed
+// standard output. The command's standard error goes to the terminal, so
+// password managers can prompt or explain failures, and its standard input is
+// empty so it never consumes gliik's piped input.
+func readAPIKeyCommand(command string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("api_key_cmd '%s' failed: %w", command, err)
+	}
+
+	key := strings.TrimSpace(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("api_key_cmd '%s' printed no key", command)
+	}
+	return key, nil
+}
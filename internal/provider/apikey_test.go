@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveAPIKey_Sources(t *testing.T) {
+	t.Setenv("GLIIK_TEST_API_KEY", "env-key")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(keyFile, []byte("file-key\n"), 0600)
+
+	tests := []struct {
+		name     string
+		settings Settings
+		expected string
+	}{
+		{"environment variable", Settings{APIKeyEnv: "GLIIK_TEST_API_KEY"}, "env-key"},
+		{"unset environment variable", Settings{APIKeyEnv: "GLIIK_TEST_UNSET_KEY"}, ""},
+		{"file over environment", Settings{APIKeyEnv: "GLIIK_TEST_API_KEY", APIKeyFile: keyFile}, "file-key"},
+		{"command over environment", Settings{APIKeyEnv: "GLIIK_TEST_API_KEY", APIKeyCmd: "printf ' cmd-key\\n'"}, "cmd-key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := resolveAPIKey(tt.settings)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, key)
+			}
+		})
+	}
+}
+
+func TestResolveAPIKey_RunsCommandOnce(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "runs")
+	settings := Settings{APIKeyCmd: "echo run >> " + counter + "; echo cached-key"}
+
+	for range 3 {
+		key, err := resolveAPIKey(settings)
+		if err != nil || key != "cached-key" {
+			t.Fatalf("expected cached-key, got %q (%v)", key, err)
+		}
+	}
+
+	runs, _ := os.ReadFile(counter)
+	if strings.Count(string(runs), "run") != 1 {
+		t.Errorf("expected the command to run once, got %q", runs)
+	}
+}
+
+func TestResolveAPIKey_Errors(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "empty")
+	os.WriteFile(emptyFile, []byte("\n"), 0600)
+
+	tests := map[string]Settings{
+		"file and command":    {APIKeyFile: emptyFile, APIKeyCmd: "echo key"},
+		"missing file":        {APIKeyFile: filepath.Join(t.TempDir(), "missing")},
+		"empty file":          {APIKeyFile: emptyFile},
+		"failing command":     {APIKeyCmd: "echo leaked-key; exit 1"},
+		"command without key": {APIKeyCmd: "true"},
+	}
+
+	for name, settings := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := resolveAPIKey(settings)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if strings.Contains(strings.ReplaceAll(err.Error(), settings.APIKeyCmd, ""), "leaked-key") {
+				t.Errorf("expected the command's output to stay out of the error, got %v", err)
+			}
+		})
+	}
+}
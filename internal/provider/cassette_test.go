@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordCassettesEnv names the environment variable listing, separated by
+// commas, the cassettes to record against the real provider APIs instead of
+// replaying them from testdata/cassettes. Recording uses the API keys from the
+// usual environment variables:
+//
+//	GLIIK_RECORD_CASSETTES=openai_stream go test ./internal/provider -run Cassettes
+//
+// Error cassettes are hard to provoke on demand and are usually written by
+// hand from the providers' documented error bodies.
+const recordCassettesEnv = "GLIIK_RECORD_CASSETTES"
+
+// cassette is one recorded HTTP exchange with a provider. The request keeps
+// only the method, path and JSON body, so API keys sent in headers or query
+// parameters are never stored. The response body is kept as the chunks it
+// arrived in, so replays reproduce lines split across network reads.
+type cassette struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Chunks  []string          `json:"chunks"`
+}
+
+// recordedHeaders are the response headers kept when recording a cassette.
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// cassettePath returns the file of the cassette called name.
+func cassettePath(name string) string {
+	return filepath.Join("testdata", "cassettes", name+".json")
+}
+
+// recordingCassette reports whether the cassette called name is recorded
+// instead of replayed.
+func recordingCassette(name string) bool {
+	return slices.Contains(strings.Split(os.Getenv(recordCassettesEnv), ","), name)
+}
+
+// cassetteAPIKey returns the API key to build a provider with: the real key
+// from envName while recording the cassette called name, skipping the test
+// when it is missing, and a placeholder while replaying.
+func cassetteAPIKey(t *testing.T, name string, envName string) string {
+	t.Helper()
+	if !recordingCassette(name) {
+		return "test-key"
+	}
+	key := os.Getenv(envName)
+	if key == "" {
+		t.Skipf("%s is not set", envName)
+	}
+	return key
+}
+
+// newCassetteServer starts a server that replays the cassette called name,
+// failing the test when the provider's request differs from the recorded one.
+// While recording, the server forwards requests to upstream instead and
+// stores the exchange as the cassette when the test ends.
+func newCassetteServer(t *testing.T, name string, upstream string) *httptest.Server {
+	t.Helper()
+
+	if recordingCassette(name) {
+		return newRecordingServer(t, name, upstream)
+	}
+
+	data, err := os.ReadFile(cassettePath(name))
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+
+	var recorded cassette
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("failed to parse cassette %s: %v", name, err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.Method != recorded.Request.Method || r.URL.Path != recorded.Request.Path {
+			t.Errorf("cassette %s: expected %s %s, got %s %s", name, recorded.Request.Method, recorded.Request.Path, r.Method, r.URL.Path)
+		}
+		if len(recorded.Request.Body) > 0 && !equalJSON(body, recorded.Request.Body) {
+			t.Errorf("cassette %s: request body differs\nexpected: %s\ngot:      %s", name, recorded.Request.Body, body)
+		}
+
+		for header, value := range recorded.Response.Headers {
+			w.Header().Set(header, value)
+		}
+		w.WriteHeader(recorded.Response.Status)
+
+		flusher, _ := w.(http.Flusher)
+		for _, chunk := range recorded.Response.Chunks {
+			io.WriteString(w, chunk)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newRecordingServer(t *testing.T, name string, upstream string) *httptest.Server {
+	t.Helper()
+
+	var recorded cassette
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		recorded.Request = cassetteRequest{Method: r.Method, Path: r.URL.Path, Body: body}
+
+		upstreamRequest, err := http.NewRequestWithContext(r.Context(), r.Method, upstream+r.URL.RequestURI(), bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("failed to create upstream request: %v", err)
+			return
+		}
+		upstreamRequest.Header = r.Header.Clone()
+
+		resp, err := http.DefaultClient.Do(upstreamRequest)
+		if err != nil {
+			t.Errorf("upstream request failed: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		recorded.Response = cassetteResponse{Status: resp.StatusCode, Headers: make(map[string]string)}
+		for _, header := range recordedHeaders {
+			if value := resp.Header.Get(header); value != "" {
+				recorded.Response.Headers[header] = value
+				w.Header().Set(header, value)
+			}
+		}
+		w.WriteHeader(resp.StatusCode)
+
+		buffer := make([]byte, 32*1024)
+		for {
+			n, err := resp.Body.Read(buffer)
+			if n > 0 {
+				recorded.Response.Chunks = append(recorded.Response.Chunks, string(buffer[:n]))
+				w.Write(buffer[:n])
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				t.Errorf("failed to read upstream response: %v", err)
+				return
+			}
+		}
+	}))
+
+	t.Cleanup(func() {
+		server.Close()
+		data, err := json.MarshalIndent(recorded, "", "  ")
+		if err != nil {
+			t.Errorf("failed to marshal cassette: %v", err)
+			return
+		}
+		if err := os.WriteFile(cassettePath(name), append(data, '\n'), 0644); err != nil {
+			t.Errorf("failed to write cassette: %v", err)
+		}
+	})
+
+	return server
+}
+
+func equalJSON(actual []byte, expected []byte) bool {
+	var actualValue, expectedValue interface{}
+	if json.Unmarshal(actual, &actualValue) != nil || json.Unmarshal(expected, &expectedValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(actualValue, expectedValue)
+}
+
+// cassetteTest describes the expected outcome of replaying a cassette.
+type cassetteTest struct {
+	cassette           string
+	expectedText       string
+	expectedFinish     string
+	expectedUsage      Usage
+	expectedErr        error
+	expectedRetryAfter time.Duration
+}
+
+// cassettePrompt returns the request recorded in the streaming cassettes.
+func cassettePrompt() Request {
+	temperature := 0.0
+	maxTokens := 50
+	return Request{
+		System:   "Be brief.",
+		Messages: UserMessage("Say hello"),
+		Options:  GenerationOptions{Temperature: &temperature, MaxTokens: &maxTokens},
+	}
+}
+
+// checkCassette sends the cassette prompt to llmProvider and compares the
+// streamed output, response and error with tt.
+func checkCassette(t *testing.T, llmProvider LLMProvider, tt cassetteTest) {
+	t.Helper()
+
+	var output strings.Builder
+	response, err := llmProvider.Complete(context.Background(), cassettePrompt(), &output)
+
+	if tt.expectedErr == nil && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+		t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+	}
+
+	if output.String() != tt.expectedText || response.Text != tt.expectedText {
+		t.Errorf("expected text %q, got %q streamed and %q returned", tt.expectedText, output.String(), response.Text)
+	}
+	if response.FinishReason != tt.expectedFinish {
+		t.Errorf("expected finish reason %q, got %q", tt.expectedFinish, response.FinishReason)
+	}
+	if response.Usage != tt.expectedUsage {
+		t.Errorf("expected usage %+v, got %+v", tt.expectedUsage, response.Usage)
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.RetryAfter != tt.expectedRetryAfter {
+		t.Errorf("expected Retry-After %s, got %s", tt.expectedRetryAfter, apiError.RetryAfter)
+	}
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors classifying why a provider rejected a request. An APIError
+// matches one of them with errors.Is, so callers can react to the kind of
+// failure without parsing messages.
+var (
+	ErrAuth          = errors.New("authentication failed")
+	ErrRateLimited   = errors.New("rate limit exceeded")
+	ErrUnavailable   = errors.New("service unavailable")
+	ErrContextLength = errors.New("context length exceeded")
+	ErrBadRequest    = errors.New("bad request")
+)
+
+// APIError is returned when a provider answers with an unsuccessful HTTP
+// status, or reports an error inside a response streamed with status 200.
+// Kind is the sentinel error the status was classified as, Body holds the raw
+// response body and RetryAfter the delay requested by the response's
+// Retry-After header, if any. StatusCode is zero for a streamed error that
+// names no HTTP status.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	Kind       error
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	detail := strings.TrimSpace(e.Body)
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %v: %s", e.Provider, e.Kind, detail)
+	}
+	if e.Kind == nil {
+		if detail == "" {
+			return fmt.Sprintf("%s API error (status %d)", e.Provider, e.StatusCode)
+		}
+		return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, detail)
+	}
+	if detail == "" {
+		return fmt.Sprintf("%s: %v (status %d)", e.Provider, e.Kind, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %v (status %d): %s", e.Provider, e.Kind, e.StatusCode, detail)
+}
+
+// Unwrap returns the sentinel error the response was classified as.
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// ConnectionError is returned when a provider's endpoint cannot be reached,
+// before any response is received.
+type ConnectionError struct {
+	Provider string
+	Endpoint string
+	Err      error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%s: cannot connect to %s: %v", e.Provider, e.Endpoint, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// StatusOverloaded is the status Anthropic returns when its API is
+// temporarily overloaded.
+const StatusOverloaded = 529
+
+// maxErrorBodySize limits how much of an error response is kept in an
+// APIError.
+const maxErrorBodySize = 64 * 1024
+
+// newAPIError reads the body of an unsuccessful response and classifies it.
+func newAPIError(providerName string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	return &APIError{
+		Provider:   providerName,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Kind:       classifyStatus(resp.StatusCode, string(body)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// newStreamAPIError classifies an error reported inside a response streamed
+// with status 200, such as Ollama's {"error": "..."} line. body is the error
+// as sent by the provider. A numeric "code" in it naming an HTTP error status
+// is classified like that status; otherwise the error is classified from its
+// text alone.
+func newStreamAPIError(providerName string, body string) *APIError {
+	var coded struct {
+		Code interface{} `json:"code"`
+	}
+	if json.Unmarshal([]byte(body), &coded) == nil {
+		if code, ok := coded.Code.(float64); ok && code >= 400 && code <= 599 {
+			return &APIError{
+				Provider:   providerName,
+				StatusCode: int(code),
+				Body:       body,
+				Kind:       classifyStatus(int(code), body),
+			}
+		}
+	}
+
+	return &APIError{Provider: providerName, Body: body, Kind: classifyStreamError(body)}
+}
+
+// hasStreamError reports whether the "error" field of a streamed chunk is set.
+func hasStreamError(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// authMarkers are fragments of the error bodies providers return with a
+// generic client error status, such as Gemini's 400, for a rejected API key.
+var authMarkers = []string{
+	"api_key_invalid",
+	"api key not valid",
+}
+
+// contextLengthMarkers are fragments of the error bodies providers return when
+// a prompt does not fit in the model's context window.
+var contextLengthMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"prompt is too long",
+	"too many tokens",
+	"input token count",
+}
+
+// rateLimitMarkers and unavailableMarkers are fragments of streamed errors
+// that name no HTTP status but report a rate limit or a server failure.
+var rateLimitMarkers = []string{
+	"rate_limit",
+	"rate limit",
+	"resource_exhausted",
+}
+
+var unavailableMarkers = []string{
+	"overloaded",
+	"server_error",
+	"unavailable",
+}
+
+// classifyStreamError maps the text of a streamed error without an HTTP
+// status to one of the sentinel errors, treating unrecognized errors as bad
+// requests.
+func classifyStreamError(body string) error {
+	switch {
+	case containsAny(body, authMarkers):
+		return ErrAuth
+	case containsAny(body, rateLimitMarkers):
+		return ErrRateLimited
+	case containsAny(body, unavailableMarkers):
+		return ErrUnavailable
+	case containsAny(body, contextLengthMarkers):
+		return ErrContextLength
+	}
+	return ErrBadRequest
+}
+
+// classifyStatus maps an HTTP status and error body to one of the sentinel
+// errors, or nil when the status fits none of them.
+func classifyStatus(statusCode int, body string) error {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrAuth
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= 500:
+		return ErrUnavailable
+	case statusCode >= 400 && containsAny(body, authMarkers):
+		return ErrAuth
+	case statusCode >= 400 && containsAny(body, contextLengthMarkers):
+		return ErrContextLength
+	case statusCode >= 400:
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// containsAny reports whether text contains one of the lowercase markers,
+// ignoring case.
+func containsAny(text string, markers []string) bool {
+	lowered := strings.ToLower(text)
+	for _, marker := range markers {
+		if strings.Contains(lowered, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date. Missing, invalid or past values return zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// IsRetryable reports whether err is a transient failure that may succeed when
+// the request is sent again: an APIError with status 429, 500, 502, 503 or
+// 529, a streamed error or Anthropic stream error reporting an overloaded
+// server or a rate limit, or a connection reset or closed before the response
+// was complete.
+func IsRetryable(err error) bool {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		if apiError.StatusCode == 0 {
+			return errors.Is(apiError, ErrUnavailable) || errors.Is(apiError, ErrRateLimited)
+		}
+		switch apiError.StatusCode {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			StatusOverloaded:
+			return true
+		}
+		return false
+	}
+
+	var streamError *AnthropicStreamError
+	if errors.As(err, &streamError) {
+		return errors.Is(streamError, ErrUnavailable) || errors.Is(streamError, ErrRateLimited)
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIError_ClassifiesResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":"invalid key"}`, ErrAuth},
+		{"forbidden", http.StatusForbidden, "", ErrAuth},
+		{"rate limited", http.StatusTooManyRequests, "", ErrRateLimited},
+		{"server error", http.StatusInternalServerError, "", ErrUnavailable},
+		{"overloaded", StatusOverloaded, `{"type":"overloaded_error"}`, ErrUnavailable},
+		{"openai context length", http.StatusBadRequest, `{"error":{"code":"context_length_exceeded"}}`, ErrContextLength},
+		{"anthropic context length", http.StatusBadRequest, `{"error":{"message":"prompt is too long: 210000 tokens"}}`, ErrContextLength},
+		{"bad request", http.StatusBadRequest, `{"error":"unknown field"}`, ErrBadRequest},
+		{"not found", http.StatusNotFound, `model not found`, ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			apiError := newAPIError("openai", resp)
+
+			if !errors.Is(apiError, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, apiError.Kind)
+			}
+			if apiError.Provider != "openai" || apiError.StatusCode != tt.statusCode || apiError.Body != tt.body {
+				t.Errorf("expected provider, status and body to be kept, got %+v", apiError)
+			}
+		})
+	}
+}
+
+func TestNewStreamAPIError_ClassifiesStreamedErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		expected   error
+	}{
+		{"ollama message", "model 'llama9' not found", 0, ErrBadRequest},
+		{"openai rate limit code", `{"message":"Rate limit reached","code":"rate_limit_exceeded"}`, 0, ErrRateLimited},
+		{"openai-compatible server error", `{"message":"internal failure","type":"server_error"}`, 0, ErrUnavailable},
+		{"numeric code", `{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}`, 429, ErrRateLimited},
+		{"numeric code with context length", `{"code":400,"message":"The input token count exceeds the maximum"}`, 400, ErrContextLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiError := newStreamAPIError("test", tt.body)
+
+			if !errors.Is(apiError, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, apiError.Kind)
+			}
+			if apiError.StatusCode != tt.statusCode || apiError.Body != tt.body {
+				t.Errorf("expected status %d and the body to be kept, got %+v", tt.statusCode, apiError)
+			}
+		})
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	apiError := &APIError{Provider: "anthropic", StatusCode: 429, Body: "slow down\n", Kind: ErrRateLimited}
+
+	expected := "anthropic: rate limit exceeded (status 429): slow down"
+	if apiError.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, apiError.Error())
+	}
+}
+
+func TestProviders_ReportConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	for _, name := range []string{"anthropic", "openai", "gemini", "ollama"} {
+		t.Run(name, func(t *testing.T) {
+			llmProvider, err := DefaultRegistry.New(name, Settings{Endpoint: endpoint, APIKeyCmd: "echo test-key"}, nil)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			_, err = llmProvider.Complete(context.Background(), Request{Messages: UserMessage("hi")}, io.Discard)
+
+			var connectionError *ConnectionError
+			if !errors.As(err, &connectionError) {
+				t.Fatalf("expected a ConnectionError, got %v", err)
+			}
+			if connectionError.Provider != name || connectionError.Endpoint != endpoint {
+				t.Errorf("expected provider '%s' and endpoint '%s', got %+v", name, endpoint, connectionError)
+			}
+			if strings.Contains(err.Error(), "\n") {
+				t.Errorf("expected a single-line error, got %q", err.Error())
+			}
+		})
+	}
+}
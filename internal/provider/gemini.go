@@ -3,23 +3,40 @@ package provider
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 )
 
 // GeminiProvider implements the LLMProvider interface for Google's Gemini API.
 type GeminiProvider struct {
-	APIKey string
-	Model  string
+	APIKey   string
+	Model    string
+	Endpoint string
+	client   *http.Client
 }
 
 var _ LLMProvider = (*GeminiProvider)(nil)
 
+func init() {
+	DefaultRegistry.Register(Registration{
+		Name: "gemini",
+		Defaults: Settings{
+			Endpoint:  "https://generativelanguage.googleapis.com/v1beta",
+			Model:     "gemini-2.0-flash",
+			APIKeyEnv: "GOOGLE_API_KEY",
+		},
+		New: func(settings Settings, client *http.Client) (LLMProvider, error) {
+			return NewGeminiProvider(settings, client)
+		},
+	})
+}
+
 type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
 	Parts []geminiPart `json:"parts"`
 }
 
@@ -27,8 +44,18 @@ type geminiPart struct {
 	Text string `json:"text"`
 }
 
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	Seed            *int     `json:"seed,omitempty"`
+}
+
 type geminiRequest struct {
-	Contents []geminiContent `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
 }
 
 type geminiStreamResponse struct {
@@ -36,99 +63,150 @@ type geminiStreamResponse struct {
 		Content struct {
 			Parts []geminiPart `json:"parts"`
 		} `json:"content"`
+		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	Error json.RawMessage `json:"error"`
 }
 
-// NewGeminiProvider creates a new GeminiProvider instance by reading the
-// GOOGLE_API_KEY environment variable and using the provided model.
-func NewGeminiProvider(model string) (*GeminiProvider, error) {
-	apiKey := os.Getenv("GOOGLE_API_KEY")
+// NewGeminiProvider creates a new GeminiProvider instance by reading the API key
+// from the source configured in settings (api_key_cmd, api_key_file or the
+// environment variable named in settings.APIKeyEnv) and using the configured
+// endpoint and model. Requests are sent with client, or with
+// http.DefaultClient when it is nil.
+func NewGeminiProvider(settings Settings, client *http.Client) (*GeminiProvider, error) {
+	apiKey, err := resolveAPIKey(settings)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable is not set\n\nTo use Gemini provider, set your API key:\n  export GOOGLE_API_KEY=your-api-key")
+		return nil, fmt.Errorf("%s environment variable is not set\n\nTo use Gemini provider, set your API key:\n  export %s=your-api-key\n\nor set api_key_file or api_key_cmd in config.yaml", settings.APIKeyEnv, settings.APIKeyEnv)
 	}
 
 	return &GeminiProvider{
-		APIKey: apiKey,
-		Model:  model,
+		APIKey:   apiKey,
+		Model:    settings.Model,
+		Endpoint: strings.TrimSuffix(settings.Endpoint, "/"),
+		client:   client,
 	}, nil
 }
 
-// StreamCompletion sends a request to the Gemini API with the given systemPrompt
-// and userMessage, then streams the response directly to stdout in real-time.
-func (g *GeminiProvider) StreamCompletion(systemPrompt, userMessage string) error {
-	reqBody := geminiRequest{
-		Contents: []geminiContent{
-			{
-				Parts: []geminiPart{
-					{Text: systemPrompt + "\n\n" + userMessage},
-				},
-			},
-		},
+// Complete sends a streaming request to the Gemini API and writes each text
+// part to w as it arrives. Assistant turns are sent with Gemini's "model" role
+// and the system prompt as systemInstruction. The API key is sent in the
+// x-goog-api-key header rather than the URL, so it never appears in errors.
+func (g *GeminiProvider) Complete(ctx context.Context, request Request, w io.Writer) (Response, error) {
+	reqBody := geminiRequest{}
+
+	for _, message := range request.Messages {
+		role := "user"
+		if message.Role == RoleAssistant {
+			role = "model"
+		}
+		reqBody.Contents = append(reqBody.Contents, geminiContent{
+			Role:  role,
+			Parts: []geminiPart{{Text: message.Content}},
+		})
+	}
+
+	if request.System != "" {
+		reqBody.SystemInstruction = &geminiContent{
+			Parts: []geminiPart{{Text: request.System}},
+		}
+	}
+
+	if !request.Options.IsZero() {
+		reqBody.GenerationConfig = &geminiGenerationConfig{
+			Temperature:     request.Options.Temperature,
+			MaxOutputTokens: request.Options.MaxTokens,
+			TopP:            request.Options.TopP,
+			StopSequences:   request.Options.Stop,
+			Seed:            request.Options.Seed,
+		}
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return Response{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", g.Model, g.APIKey)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", g.Endpoint, g.Model)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return Response{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.APIKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient(g.client).Do(req)
 	if err != nil {
-		return fmt.Errorf("Error: Cannot connect to Gemini API\n\nPlease check your network connection.\n\nError: %w", err)
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+		return Response{}, &ConnectionError{Provider: "gemini", Endpoint: g.Endpoint, Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-
-		switch resp.StatusCode {
-		case http.StatusUnauthorized, http.StatusForbidden:
-			return fmt.Errorf("Error: Invalid Gemini API key\n\nThe GOOGLE_API_KEY you provided is invalid or expired.\nPlease check your API key at: https://aistudio.google.com/app/apikey")
-		case http.StatusTooManyRequests:
-			return fmt.Errorf("Error: Gemini rate limit exceeded\n\nYou have exceeded your API rate limit.\nPlease wait a moment and try again.")
-		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
-			return fmt.Errorf("Error: Gemini service unavailable\n\nGemini's servers are experiencing issues. Please try again later.\nStatus: %d", resp.StatusCode)
-		default:
-			return fmt.Errorf("Gemini API error (status %d): %s", resp.StatusCode, string(body))
-		}
+		return Response{}, newAPIError("gemini", resp)
 	}
 
+	stream := &responseStream{writer: w}
+	var response Response
+
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
-		line := scanner.Text()
-
-		if !strings.HasPrefix(line, "data: ") {
+		data, isData := sseData(scanner.Text())
+		if !isData || data == "[DONE]" || data == "" {
 			continue
 		}
 
-		data := strings.TrimPrefix(line, "data: ")
-		if data == "[DONE]" || data == "" {
+		var streamResp geminiStreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
 			continue
 		}
 
-		var streamResp geminiStreamResponse
-		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+		if hasStreamError(streamResp.Error) {
+			response.Text = stream.text.String()
+			return response, newStreamAPIError("gemini", string(streamResp.Error))
+		}
+
+		if streamResp.UsageMetadata.PromptTokenCount > 0 {
+			response.Usage.InputTokens = streamResp.UsageMetadata.PromptTokenCount
+			response.Usage.OutputTokens = streamResp.UsageMetadata.CandidatesTokenCount
+		}
+
+		if len(streamResp.Candidates) == 0 {
 			continue
 		}
 
-		if len(streamResp.Candidates) > 0 && len(streamResp.Candidates[0].Content.Parts) > 0 {
-			if text := streamResp.Candidates[0].Content.Parts[0].Text; text != "" {
-				fmt.Print(text)
+		candidate := streamResp.Candidates[0]
+		if candidate.FinishReason != "" {
+			response.FinishReason = candidate.FinishReason
+		}
+
+		for _, part := range candidate.Content.Parts {
+			if err := stream.write(part.Text); err != nil {
+				response.Text = stream.text.String()
+				return response, err
 			}
 		}
 	}
 
+	response.Text = stream.text.String()
+
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading stream: %w", err)
+		return response, stream.streamError(ctx, fmt.Errorf("error reading stream: %w", err))
+	}
+
+	if response.FinishReason == "" {
+		return response, stream.streamError(ctx, fmt.Errorf("stream ended without a finish reason: %w", io.ErrUnexpectedEOF))
 	}
 
-	return nil
+	return response, ctx.Err()
 }
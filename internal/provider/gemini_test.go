@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiProvider_Cassettes(t *testing.T) {
+	tests := []cassetteTest{
+		{
+			cassette:       "gemini_stream",
+			expectedText:   "Hello! How can I help?",
+			expectedFinish: "STOP",
+			expectedUsage:  Usage{InputTokens: 10, OutputTokens: 8},
+		},
+		{
+			cassette:       "gemini_multipart_stream",
+			expectedText:   "Hello! How can I help?",
+			expectedFinish: "STOP",
+			expectedUsage:  Usage{InputTokens: 10, OutputTokens: 8},
+		},
+		{cassette: "gemini_stream_error", expectedText: "Hello!", expectedErr: ErrContextLength},
+		{cassette: "gemini_truncated", expectedText: "Hello!", expectedErr: io.ErrUnexpectedEOF},
+		{cassette: "gemini_rate_limited", expectedErr: ErrRateLimited},
+		{cassette: "gemini_invalid_key", expectedErr: ErrAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			server := newCassetteServer(t, tt.cassette, "https://generativelanguage.googleapis.com")
+			gemini := &GeminiProvider{
+				APIKey:   cassetteAPIKey(t, tt.cassette, "GOOGLE_API_KEY"),
+				Model:    "gemini-2.0-flash",
+				Endpoint: server.URL + "/v1beta",
+			}
+
+			checkCassette(t, gemini, tt)
+		})
+	}
+}
+
+func TestGeminiProvider_SendsKeyInHeader(t *testing.T) {
+	var query, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		key = r.Header.Get("x-goog-api-key")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	gemini := &GeminiProvider{APIKey: "secret-key", Model: "gemini-2.0-flash", Endpoint: server.URL}
+	_, err := gemini.Complete(context.Background(), cassettePrompt(), io.Discard)
+
+	if key != "secret-key" {
+		t.Errorf("expected the key in the x-goog-api-key header, got %q", key)
+	}
+	if strings.Contains(query, "secret-key") || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("expected the key to stay out of the URL and error, got query %q and error %v", query, err)
+	}
+}
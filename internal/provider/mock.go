@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MockProvider is an offline LLMProvider for tests, CI and trying out
+// instructions without an API key. It answers from the fixture files in
+// FixturesDir, or echoes the rendered prompt when FixturesDir is empty, and
+// can simulate chunked streaming, latency and HTTP errors.
+type MockProvider struct {
+	FixturesDir string
+	ChunkSize   int
+	Latency     time.Duration
+	ErrorStatus int
+	ErrorBody   string
+}
+
+var _ LLMProvider = (*MockProvider)(nil)
+
+func init() {
+	DefaultRegistry.Register(Registration{
+		Name:     "mock",
+		Defaults: Settings{Model: "mock"},
+		Unlisted: true,
+		New: func(settings Settings, client *http.Client) (LLMProvider, error) {
+			return NewMockProvider(settings)
+		},
+		Validate: func(settings Settings) error {
+			_, err := NewMockProvider(settings)
+			return err
+		},
+	})
+}
+
+// NewMockProvider creates a MockProvider from the options of settings:
+// "fixtures" (directory of fixture files), "chunk_size" (characters per
+// streamed chunk, 0 for a single chunk), "latency" (delay before each chunk,
+// as a Go duration), "error_status" (HTTP status to fail every request with)
+// and "error_body" (response body of the simulated error).
+func NewMockProvider(settings Settings) (*MockProvider, error) {
+	mock := &MockProvider{}
+
+	for option, value := range settings.Options {
+		var err error
+		switch option {
+		case "fixtures":
+			mock.FixturesDir = value
+		case "chunk_size":
+			mock.ChunkSize, err = strconv.Atoi(value)
+			if err == nil && mock.ChunkSize < 0 {
+				err = errors.New("must not be negative")
+			}
+		case "latency":
+			mock.Latency, err = time.ParseDuration(value)
+		case "error_status":
+			mock.ErrorStatus, err = strconv.Atoi(value)
+			if err == nil && (mock.ErrorStatus < 400 || mock.ErrorStatus > 599) {
+				err = errors.New("must be an HTTP error status between 400 and 599")
+			}
+		case "error_body":
+			mock.ErrorBody = value
+		default:
+			return nil, fmt.Errorf("unknown mock provider option '%s': must be one of fixtures, chunk_size, latency, error_status, error_body", option)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock provider option %s '%s': %w", option, value, err)
+		}
+	}
+
+	return mock, nil
+}
+
+// Complete writes the fixture for request, or the echoed prompt, to w in
+// chunks of ChunkSize characters, waiting Latency before each one. When
+// ErrorStatus is set, it fails with the APIError a provider answering with
+// that status would return.
+func (m *MockProvider) Complete(ctx context.Context, request Request, w io.Writer) (Response, error) {
+	if m.ErrorStatus != 0 {
+		if err := Sleep(ctx, m.Latency); err != nil {
+			return Response{}, err
+		}
+		return Response{}, &APIError{
+			Provider:   "mock",
+			StatusCode: m.ErrorStatus,
+			Body:       m.ErrorBody,
+			Kind:       classifyStatus(m.ErrorStatus, m.ErrorBody),
+		}
+	}
+
+	text, err := m.responseText(request)
+	if err != nil {
+		return Response{}, err
+	}
+
+	stream := &responseStream{writer: w}
+	response := Response{
+		FinishReason: "stop",
+		Usage: Usage{
+			InputTokens:  countWords(echoPrompt(request)),
+			OutputTokens: countWords(text),
+		},
+	}
+
+	for _, chunk := range splitChunks(text, m.ChunkSize) {
+		if err := Sleep(ctx, m.Latency); err != nil {
+			response.Text = stream.text.String()
+			return response, err
+		}
+		if err := stream.write(chunk); err != nil {
+			response.Text = stream.text.String()
+			return response, err
+		}
+	}
+
+	response.Text = stream.text.String()
+	return response, nil
+}
+
+// responseText returns the content of the first fixture file found for
+// request, named after its prompt hash or its instruction name with a .txt
+// extension, or the echoed prompt when no fixtures directory is configured.
+func (m *MockProvider) responseText(request Request) (string, error) {
+	if m.FixturesDir == "" {
+		return echoPrompt(request), nil
+	}
+
+	candidates := []string{HashPrompt(request.System, request.Messages) + ".txt"}
+	if request.Name != "" {
+		candidates = append(candidates, request.Name+".txt")
+	}
+
+	for _, candidate := range candidates {
+		content, err := os.ReadFile(filepath.Join(m.FixturesDir, candidate))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read mock fixture: %w", err)
+		}
+	}
+
+	return "", fmt.Errorf("no mock fixture for this request in %s (looked for %s)", m.FixturesDir, strings.Join(candidates, ", "))
+}
+
+// echoPrompt renders request in the multi-message instruction format.
+func echoPrompt(request Request) string {
+	var sections []string
+	if request.System != "" {
+		sections = append(sections, "## system\n"+request.System)
+	}
+	for _, message := range request.Messages {
+		sections = append(sections, fmt.Sprintf("## %s\n%s", message.Role, message.Content))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// splitChunks splits text into chunks of size characters, or returns it as a
+// single chunk when size is zero.
+func splitChunks(text string, size int) []string {
+	runes := []rune(text)
+	if size <= 0 || len(runes) <= size {
+		return []string{text}
+	}
+
+	var chunks []string
+	for start := 0; start < len(runes); start += size {
+		end := min(start+size, len(runes))
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	return chunks
+}
+
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type chunkRecorder struct {
+	chunks []string
+}
+
+func (r *chunkRecorder) Write(p []byte) (int, error) {
+	r.chunks = append(r.chunks, string(p))
+	return len(p), nil
+}
+
+func newTestMockProvider(t *testing.T, options map[string]string) *MockProvider {
+	t.Helper()
+	mock, err := NewMockProvider(Settings{Options: options})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return mock
+}
+
+func TestMockProvider_EchoesPrompt(t *testing.T) {
+	mock := newTestMockProvider(t, nil)
+	request := Request{System: "Be brief.", Messages: UserMessage("Summarize this")}
+
+	var output strings.Builder
+	response, err := mock.Complete(context.Background(), request, &output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "## system\nBe brief.\n\n## user\nSummarize this\n"
+	if output.String() != expected || response.Text != expected {
+		t.Errorf("expected the echoed prompt %q, got %q", expected, output.String())
+	}
+	if response.Usage.InputTokens == 0 || response.Usage.OutputTokens == 0 {
+		t.Errorf("expected simulated token usage, got %+v", response.Usage)
+	}
+}
+
+func TestMockProvider_Fixtures(t *testing.T) {
+	fixturesDir := t.TempDir()
+	byHash := Request{Name: "summarize", Messages: UserMessage("known input")}
+	byName := Request{Name: "summarize", Messages: UserMessage("other input")}
+
+	os.WriteFile(filepath.Join(fixturesDir, HashPrompt(byHash.System, byHash.Messages)+".txt"), []byte("hash fixture"), 0644)
+	os.WriteFile(filepath.Join(fixturesDir, "summarize.txt"), []byte("name fixture"), 0644)
+
+	mock := newTestMockProvider(t, map[string]string{"fixtures": fixturesDir})
+
+	tests := []struct {
+		name     string
+		request  Request
+		expected string
+	}{
+		{"prompt hash takes precedence", byHash, "hash fixture"},
+		{"instruction name", byName, "name fixture"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			if _, err := mock.Complete(context.Background(), tt.request, &output); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, output.String())
+			}
+		})
+	}
+
+	_, err := mock.Complete(context.Background(), Request{Name: "review", Messages: UserMessage("x")}, &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "review.txt") {
+		t.Errorf("expected a missing fixture error naming review.txt, got %v", err)
+	}
+}
+
+func TestMockProvider_StreamsChunks(t *testing.T) {
+	mock := newTestMockProvider(t, map[string]string{"chunk_size": "4"})
+	mock.FixturesDir = t.TempDir()
+	os.WriteFile(filepath.Join(mock.FixturesDir, "story.txt"), []byte("once upon"), 0644)
+
+	recorder := &chunkRecorder{}
+	if _, err := mock.Complete(context.Background(), Request{Name: "story"}, recorder); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"once", " upo", "n"}
+	if strings.Join(recorder.chunks, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected chunks %q, got %q", expected, recorder.chunks)
+	}
+}
+
+func TestMockProvider_SimulatesErrors(t *testing.T) {
+	mock := newTestMockProvider(t, map[string]string{"error_status": "429", "error_body": "slow down"})
+
+	_, err := mock.Complete(context.Background(), Request{}, &strings.Builder{})
+
+	if !errors.Is(err, ErrRateLimited) || !IsRetryable(err) {
+		t.Errorf("expected a retryable rate limit error, got %v", err)
+	}
+}
+
+func TestMockProvider_StopsWhenCancelled(t *testing.T) {
+	mock := newTestMockProvider(t, map[string]string{"chunk_size": "1", "latency": "1h"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := mock.Complete(ctx, Request{Messages: UserMessage("hello")}, &strings.Builder{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+func TestNewMockProvider_InvalidOptions(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unknown option":     {"speed": "fast"},
+		"invalid latency":    {"latency": "soon"},
+		"negative chunk":     {"chunk_size": "-1"},
+		"non-error status":   {"error_status": "200"},
+		"non-numeric status": {"error_status": "teapot"},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewMockProvider(Settings{Options: options}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
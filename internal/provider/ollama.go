@@ -3,9 +3,12 @@ package provider
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 )
 
 // OllamaProvider implements the LLMProvider interface for Ollama's local LLM runtime.
@@ -13,78 +16,150 @@ import (
 type OllamaProvider struct {
 	Endpoint string
 	Model    string
+	client   *http.Client
 }
 
 var _ LLMProvider = (*OllamaProvider)(nil)
 
-// NewOllamaProvider creates a new OllamaProvider instance with the specified endpoint
+func init() {
+	DefaultRegistry.Register(Registration{
+		Name:     "ollama",
+		Defaults: Settings{Endpoint: "http://localhost:11434", Model: "llama3.2"},
+		New: func(settings Settings, client *http.Client) (LLMProvider, error) {
+			return NewOllamaProvider(settings, client), nil
+		},
+	})
+}
+
+type ollamaStreamResponse struct {
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+// NewOllamaProvider creates a new OllamaProvider instance with the configured endpoint
 // and model. The endpoint should be the full URL to the Ollama server (e.g.,
-// "http://localhost:11434"), and the model should be a valid Ollama model name.
-func NewOllamaProvider(endpoint, model string) *OllamaProvider {
+// "http://localhost:11434"; a trailing slash is removed), and the model should
+// be a valid Ollama model name.
+// Requests are sent with client, or with http.DefaultClient when it is nil.
+func NewOllamaProvider(settings Settings, client *http.Client) *OllamaProvider {
 	return &OllamaProvider{
-		Endpoint: endpoint,
-		Model:    model,
+		Endpoint: strings.TrimRight(settings.Endpoint, "/"),
+		Model:    settings.Model,
+		client:   client,
 	}
 }
 
-// StreamCompletion sends a request to the Ollama server with the given systemPrompt
-// and userMessage, then streams the response directly to stdout in real-time.
-// The systemPrompt and userMessage are combined into a single prompt for Ollama.
-// Returns an error if the connection fails or if there's an issue with the response.
-func (o *OllamaProvider) StreamCompletion(systemPrompt, userMessage string) error {
-	combinedPrompt := systemPrompt + "\n\n" + userMessage
+// Complete sends a request to Ollama's chat endpoint and writes each response
+// chunk to w as it arrives. The system prompt is sent as the first message of
+// the conversation. Returns an error if the connection fails or if there's an
+// issue with the response.
+func (o *OllamaProvider) Complete(ctx context.Context, request Request, w io.Writer) (Response, error) {
+	messages := []Message{}
+	if request.System != "" {
+		messages = append(messages, Message{Role: "system", Content: request.System})
+	}
+	messages = append(messages, request.Messages...)
 
 	reqBody := map[string]interface{}{
-		"model":  o.Model,
-		"prompt": combinedPrompt,
-		"stream": true,
+		"model":    o.Model,
+		"messages": messages,
+		"stream":   true,
+	}
+
+	if options := ollamaOptions(request.Options); len(options) > 0 {
+		reqBody["options"] = options
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return Response{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/generate", o.Endpoint)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	url := fmt.Sprintf("%s/api/chat", o.Endpoint)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return Response{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient(o.client).Do(req)
 	if err != nil {
-		return fmt.Errorf("Error: Cannot connect to Ollama\n\nMake sure Ollama is running:\n  ollama serve\n\nConfigured endpoint: %s", o.Endpoint)
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+		return Response{}, &ConnectionError{Provider: "ollama", Endpoint: o.Endpoint, Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Ollama API error (status %d)", resp.StatusCode)
+		return Response{}, newAPIError("ollama", resp)
 	}
 
+	stream := &responseStream{writer: w}
+	var response Response
+
+	done := false
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
-		line := scanner.Bytes()
-
-		var response map[string]interface{}
-		if err := json.Unmarshal(line, &response); err != nil {
+		var chunk ollamaStreamResponse
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
 			continue
 		}
 
-		if done, ok := response["done"].(bool); ok && done {
-			break
+		if chunk.Error != "" {
+			response.Text = stream.text.String()
+			return response, newStreamAPIError("ollama", chunk.Error)
+		}
+
+		if err := stream.write(chunk.Message.Content); err != nil {
+			response.Text = stream.text.String()
+			return response, err
 		}
 
-		if responseText, ok := response["response"].(string); ok {
-			fmt.Print(responseText)
+		if chunk.Done {
+			done = true
+			response.FinishReason = chunk.DoneReason
+			response.Usage.InputTokens = chunk.PromptEvalCount
+			response.Usage.OutputTokens = chunk.EvalCount
+			break
 		}
 	}
 
+	response.Text = stream.text.String()
+
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading response: %w", err)
+		return response, stream.streamError(ctx, fmt.Errorf("error reading response: %w", err))
 	}
 
-	return nil
+	if !done {
+		return response, stream.streamError(ctx, fmt.Errorf("response ended before done: %w", io.ErrUnexpectedEOF))
+	}
+
+	return response, ctx.Err()
+}
+
+func ollamaOptions(generationOptions GenerationOptions) map[string]interface{} {
+	options := map[string]interface{}{}
+	if generationOptions.Temperature != nil {
+		options["temperature"] = *generationOptions.Temperature
+	}
+	if generationOptions.MaxTokens != nil {
+		options["num_predict"] = *generationOptions.MaxTokens
+	}
+	if generationOptions.TopP != nil {
+		options["top_p"] = *generationOptions.TopP
+	}
+	if len(generationOptions.Stop) > 0 {
+		options["stop"] = generationOptions.Stop
+	}
+	if generationOptions.Seed != nil {
+		options["seed"] = *generationOptions.Seed
+	}
+	return options
 }
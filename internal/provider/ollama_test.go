@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"io"
+	"testing"
+)
+
+func TestOllamaProvider_Cassettes(t *testing.T) {
+	tests := []cassetteTest{
+		{
+			cassette:       "ollama_stream",
+			expectedText:   "Hello! How can I help?",
+			expectedFinish: "stop",
+			expectedUsage:  Usage{InputTokens: 26, OutputTokens: 9},
+		},
+		{cassette: "ollama_stream_error", expectedText: "Hello!", expectedErr: ErrBadRequest},
+		{cassette: "ollama_truncated", expectedText: "Hello!", expectedErr: io.ErrUnexpectedEOF},
+		{cassette: "ollama_model_not_found", expectedErr: ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			server := newCassetteServer(t, tt.cassette, "http://localhost:11434")
+			ollama := NewOllamaProvider(Settings{Endpoint: server.URL, Model: "llama3.2"}, nil)
+
+			checkCassette(t, ollama, tt)
+		})
+	}
+}
+
+func TestOllamaProvider_EndpointWithTrailingSlash(t *testing.T) {
+	tt := cassetteTest{
+		cassette:       "ollama_stream",
+		expectedText:   "Hello! How can I help?",
+		expectedFinish: "stop",
+		expectedUsage:  Usage{InputTokens: 26, OutputTokens: 9},
+	}
+
+	server := newCassetteServer(t, tt.cassette, "http://localhost:11434")
+	ollama := NewOllamaProvider(Settings{Endpoint: server.URL + "/", Model: "llama3.2"}, nil)
+
+	checkCassette(t, ollama, tt)
+}
@@ -3,32 +3,68 @@ package provider
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 )
 
 // OpenAIProvider implements the LLMProvider interface for OpenAI's API.
 // It handles authentication and communication with OpenAI or OpenAI-compatible endpoints.
+// ExcludeUsage leaves stream_options out of requests for compatible servers
+// that reject it; those responses report no token usage.
 type OpenAIProvider struct {
-	APIKey   string
-	Model    string
-	Endpoint string
+	APIKey       string
+	Model        string
+	Endpoint     string
+	ExcludeUsage bool
+	client       *http.Client
 }
 
 var _ LLMProvider = (*OpenAIProvider)(nil)
 
-// NewOpenAIProvider creates a new OpenAIProvider instance by reading the
-// OPENAI_API_KEY environment variable and using the provided endpoint and model.
-// Returns an error with clear instructions if the API key is not set.
-func NewOpenAIProvider(endpoint, model string) (*OpenAIProvider, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
+func init() {
+	DefaultRegistry.Register(Registration{
+		Name: "openai",
+		Defaults: Settings{
+			Endpoint:  "https://api.openai.com/v1",
+			Model:     "gpt-4o-mini",
+			APIKeyEnv: "OPENAI_API_KEY",
+		},
+		New: func(settings Settings, client *http.Client) (LLMProvider, error) {
+			return NewOpenAIProvider(settings, client)
+		},
+		Validate: func(settings Settings) error {
+			_, err := parseOpenAIOptions(settings)
+			return err
+		},
+	})
+}
+
+// NewOpenAIProvider creates a new OpenAIProvider instance by reading the API key
+// from the source configured in settings (api_key_cmd, api_key_file or the
+// environment variable named in settings.APIKeyEnv) and using the configured
+// endpoint, model and options. Returns an error with clear instructions if the
+// API key is not set. Requests are sent with client, or with http.DefaultClient
+// when it is nil.
+func NewOpenAIProvider(settings Settings, client *http.Client) (*OpenAIProvider, error) {
+	excludeUsage, err := parseOpenAIOptions(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := resolveAPIKey(settings)
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set\n\nTo use OpenAI provider, set your API key:\n  export OPENAI_API_KEY=sk-...")
+		return nil, fmt.Errorf("%s environment variable is not set\n\nTo use OpenAI provider, set your API key:\n  export %s=sk-...\n\nor set api_key_file or api_key_cmd in config.yaml", settings.APIKeyEnv, settings.APIKeyEnv)
 	}
 
+	endpoint := settings.Endpoint
 	if endpoint == "" {
 		return nil, fmt.Errorf("OpenAI endpoint cannot be empty")
 	}
@@ -40,21 +76,54 @@ func NewOpenAIProvider(endpoint, model string) (*OpenAIProvider, error) {
 	normalizedEndpoint := strings.TrimSuffix(endpoint, "/")
 
 	return &OpenAIProvider{
-		APIKey:   apiKey,
-		Model:    model,
-		Endpoint: normalizedEndpoint,
+		APIKey:       apiKey,
+		Model:        settings.Model,
+		Endpoint:     normalizedEndpoint,
+		ExcludeUsage: excludeUsage,
+		client:       client,
 	}, nil
 }
 
+// parseOpenAIOptions reads the options of settings: "include_usage" ("false"
+// for servers that reject stream_options, such as older vLLM releases, some
+// LiteLLM proxies and Azure API versions before 2024-09). It returns whether
+// usage is excluded from requests.
+func parseOpenAIOptions(settings Settings) (bool, error) {
+	excludeUsage := false
+	for option, value := range settings.Options {
+		switch option {
+		case "include_usage":
+			includeUsage, err := strconv.ParseBool(value)
+			if err != nil {
+				return false, fmt.Errorf("invalid openai provider option %s '%s': must be true or false", option, value)
+			}
+			excludeUsage = !includeUsage
+		default:
+			return false, fmt.Errorf("unknown openai provider option '%s': must be include_usage", option)
+		}
+	}
+	return excludeUsage, nil
+}
+
 type openAIMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 }
 
 type openAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	MaxTokens     *int                 `json:"max_tokens,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	Stop          []string             `json:"stop,omitempty"`
+	Seed          *int                 `json:"seed,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openAIStreamResponse struct {
@@ -64,81 +133,88 @@ type openAIStreamResponse struct {
 		} `json:"delta"`
 		FinishReason *string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error json.RawMessage `json:"error"`
 }
 
-// StreamCompletion sends a request to the OpenAI API with the given systemPrompt
-// and userMessage, then streams the response directly to stdout in real-time.
-// The systemPrompt and userMessage are sent as separate messages in the conversation.
-func (o *OpenAIProvider) StreamCompletion(systemPrompt, userMessage string) error {
+// Complete sends a streaming request to the OpenAI API and writes each content
+// delta to w as it arrives. The system prompt is sent as the first message of
+// the conversation.
+func (o *OpenAIProvider) Complete(ctx context.Context, request Request, w io.Writer) (Response, error) {
 	messages := []openAIMessage{}
 
-	if systemPrompt != "" {
+	if request.System != "" {
 		messages = append(messages, openAIMessage{
 			Role:    "system",
-			Content: systemPrompt,
+			Content: request.System,
 		})
 	}
 
-	messages = append(messages, openAIMessage{
-		Role:    "user",
-		Content: userMessage,
-	})
+	for _, message := range request.Messages {
+		messages = append(messages, openAIMessage{
+			Role:    message.Role,
+			Content: message.Content,
+		})
+	}
 
 	reqBody := openAIRequest{
-		Model:    o.Model,
-		Messages: messages,
-		Stream:   true,
+		Model:       o.Model,
+		Messages:    messages,
+		Stream:      true,
+		Temperature: request.Options.Temperature,
+		MaxTokens:   request.Options.MaxTokens,
+		TopP:        request.Options.TopP,
+		Stop:        request.Options.Stop,
+		Seed:        request.Options.Seed,
+	}
+	if !o.ExcludeUsage {
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return Response{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := o.Endpoint + "/chat/completions"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return Response{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+o.APIKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient(o.client).Do(req)
 	if err != nil {
-		return fmt.Errorf("Error: Cannot connect to OpenAI API\n\nPlease check your network connection and endpoint configuration.\n\nConfigured endpoint: %s\nError: %w", o.Endpoint, err)
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+		return Response{}, &ConnectionError{Provider: "openai", Endpoint: o.Endpoint, Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := bufio.NewReader(resp.Body).ReadString('\n')
-
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			return fmt.Errorf("Error: Invalid OpenAI API key\n\nThe OPENAI_API_KEY you provided is invalid or expired.\nPlease check your API key at: https://platform.openai.com/api-keys")
-		case http.StatusTooManyRequests:
-			return fmt.Errorf("Error: OpenAI rate limit exceeded\n\nYou have exceeded your API rate limit.\nPlease wait a moment and try again, or check your usage at: https://platform.openai.com/usage")
-		case http.StatusForbidden:
-			return fmt.Errorf("Error: Access forbidden\n\nYour API key does not have permission to access this resource.\nPlease check your API key permissions.")
-		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
-			return fmt.Errorf("Error: OpenAI service unavailable\n\nOpenAI's servers are experiencing issues. Please try again later.\nStatus: %d", resp.StatusCode)
-		default:
-			return fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, body)
-		}
+		return Response{}, newAPIError("openai", resp)
 	}
 
+	stream := &responseStream{writer: w}
+	var response Response
+
+	done := false
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
-		line := scanner.Text()
-
-		if !strings.HasPrefix(line, "data: ") {
+		data, isData := sseData(scanner.Text())
+		if !isData {
 			continue
 		}
 
-		data := strings.TrimPrefix(line, "data: ")
-
 		if data == "[DONE]" {
+			done = true
 			break
 		}
 
@@ -147,17 +223,36 @@ func (o *OpenAIProvider) StreamCompletion(systemPrompt, userMessage string) erro
 			continue
 		}
 
+		if hasStreamError(streamResp.Error) {
+			response.Text = stream.text.String()
+			return response, newStreamAPIError("openai", string(streamResp.Error))
+		}
+
 		if len(streamResp.Choices) > 0 {
-			content := streamResp.Choices[0].Delta.Content
-			if content != "" {
-				fmt.Print(content)
+			if err := stream.write(streamResp.Choices[0].Delta.Content); err != nil {
+				response.Text = stream.text.String()
+				return response, err
 			}
+			if finishReason := streamResp.Choices[0].FinishReason; finishReason != nil {
+				response.FinishReason = *finishReason
+			}
+		}
+
+		if streamResp.Usage != nil {
+			response.Usage.InputTokens = streamResp.Usage.PromptTokens
+			response.Usage.OutputTokens = streamResp.Usage.CompletionTokens
 		}
 	}
 
+	response.Text = stream.text.String()
+
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading stream: %w", err)
+		return response, stream.streamError(ctx, fmt.Errorf("error reading stream: %w", err))
+	}
+
+	if !done && response.FinishReason == "" {
+		return response, stream.streamError(ctx, fmt.Errorf("stream ended before [DONE]: %w", io.ErrUnexpectedEOF))
 	}
 
-	return nil
+	return response, ctx.Err()
 }
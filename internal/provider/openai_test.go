@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOpenAIProvider_Cassettes(t *testing.T) {
+	tests := []cassetteTest{
+		{
+			cassette:       "openai_stream",
+			expectedText:   "Hello! How can I help?",
+			expectedFinish: "stop",
+			expectedUsage:  Usage{InputTokens: 14, OutputTokens: 8},
+		},
+		{
+			cassette:       "openai_compatible_stream",
+			expectedText:   "Hi there",
+			expectedFinish: "stop",
+		},
+		{cassette: "openai_stream_error", expectedText: "Hello!", expectedErr: ErrRateLimited},
+		{cassette: "openai_truncated", expectedText: "Hello!", expectedErr: io.ErrUnexpectedEOF},
+		{cassette: "openai_rate_limited", expectedErr: ErrRateLimited, expectedRetryAfter: 20 * time.Second},
+		{cassette: "openai_context_length", expectedErr: ErrContextLength},
+		{cassette: "openai_stream_options_rejected", expectedErr: ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			server := newCassetteServer(t, tt.cassette, "https://api.openai.com")
+			openAI := &OpenAIProvider{
+				APIKey:   cassetteAPIKey(t, tt.cassette, "OPENAI_API_KEY"),
+				Model:    "gpt-4o-mini",
+				Endpoint: server.URL + "/v1",
+			}
+
+			checkCassette(t, openAI, tt)
+		})
+	}
+}
+
+func TestOpenAIProvider_IncludeUsageOption(t *testing.T) {
+	tt := cassetteTest{
+		cassette:       "openai_stream_without_usage",
+		expectedText:   "Hello!",
+		expectedFinish: "stop",
+	}
+
+	server := newCassetteServer(t, tt.cassette, "https://api.openai.com")
+	t.Setenv("GLIIK_TEST_OPENAI_KEY", cassetteAPIKey(t, tt.cassette, "OPENAI_API_KEY"))
+	openAI, err := NewOpenAIProvider(Settings{
+		Endpoint:  server.URL + "/v1",
+		Model:     "gpt-4o-mini",
+		APIKeyEnv: "GLIIK_TEST_OPENAI_KEY",
+		Options:   map[string]string{"include_usage": "false"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkCassette(t, openAI, tt)
+}
+
+func TestOpenAIProvider_InvalidOptions(t *testing.T) {
+	for _, options := range []map[string]string{{"include_usage": "maybe"}, {"stream_options": "false"}} {
+		if err := DefaultRegistry.Validate("openai", Settings{Options: options}); err == nil {
+			t.Errorf("expected options %v to be rejected", options)
+		}
+	}
+}
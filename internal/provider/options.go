@@ -0,0 +1,40 @@
+package provider
+
+// GenerationOptions holds the sampling parameters shared by all providers.
+// Nil fields are left unset so each provider falls back to its own default.
+// Each provider maps the options to its own wire format and ignores the ones
+// its API does not support, such as Seed for Anthropic.
+type GenerationOptions struct {
+	Temperature *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	MaxTokens   *int     `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	TopP        *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
+	Stop        []string `yaml:"stop,omitempty" json:"stop,omitempty"`
+	Seed        *int     `yaml:"seed,omitempty" json:"seed,omitempty"`
+}
+
+// Merge returns a copy of o where every field set in override replaces the
+// corresponding field of o.
+func (o GenerationOptions) Merge(override GenerationOptions) GenerationOptions {
+	merged := o
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.MaxTokens != nil {
+		merged.MaxTokens = override.MaxTokens
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.Stop != nil {
+		merged.Stop = override.Stop
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	return merged
+}
+
+// IsZero reports whether no option is set.
+func (o GenerationOptions) IsZero() bool {
+	return o.Temperature == nil && o.MaxTokens == nil && o.TopP == nil && o.Stop == nil && o.Seed == nil
+}
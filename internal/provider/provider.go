@@ -1,11 +1,107 @@
 package provider
 
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
 // LLMProvider defines the interface for language model providers that can execute
-// AI completions with streaming output. Implementations include Anthropic's Claude
-// API and Ollama for local model execution.
+// AI completions with streaming output. Implementations include Anthropic's Claude,
+// OpenAI-compatible APIs, Google Gemini and Ollama for local model execution.
 type LLMProvider interface {
-	// StreamCompletion sends a prompt to the language model and streams the response
-	// to stdout in real-time. The systemPrompt provides context and instructions,
-	// while userMessage contains the actual user input or request.
-	StreamCompletion(systemPrompt, userMessage string) error
+	// Complete sends the request to the language model and writes each chunk of
+	// the response to w as it arrives. The call is aborted when ctx is cancelled
+	// or its deadline expires, in which case the partial Response is returned
+	// together with the context error.
+	Complete(ctx context.Context, req Request, w io.Writer) (Response, error)
+}
+
+// Message roles used in Request.Messages.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request holds the prompts for a single completion call. System provides
+// context and instructions, while Messages holds the ordered conversation
+// turns, alternating between RoleUser and RoleAssistant and usually ending
+// with a user turn. Name is the instruction that rendered the prompts; it is
+// never sent to a model, but lets the mock provider pick a fixture.
+type Request struct {
+	Name     string
+	System   string
+	Messages []Message
+	Options  GenerationOptions
+}
+
+// HashPrompt returns a stable hex digest of the rendered system prompt and
+// messages, identifying a prompt regardless of the options it was sent with.
+func HashPrompt(system string, messages []Message) string {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	encoder.Encode(system)
+	encoder.Encode(messages)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// UserMessage returns a single-turn conversation holding content as the user
+// message.
+func UserMessage(content string) []Message {
+	return []Message{{Role: RoleUser, Content: content}}
+}
+
+// Response is the structured result of a completion call. Cached is set when
+// the response was replayed from the response cache instead of generated.
+type Response struct {
+	Text         string
+	FinishReason string
+	Usage        Usage
+	Cached       bool
+}
+
+// Usage reports the token counts of a completion when the provider returns them.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type responseStream struct {
+	writer io.Writer
+	text   strings.Builder
+}
+
+func (s *responseStream) write(chunk string) error {
+	if chunk == "" {
+		return nil
+	}
+	s.text.WriteString(chunk)
+	_, err := io.WriteString(s.writer, chunk)
+	return err
+}
+
+// sseData returns the payload of a server-sent events "data:" line, whose
+// space after the colon is optional, and whether line is one.
+func sseData(line string) (string, bool) {
+	data, found := strings.CutPrefix(line, "data:")
+	if !found {
+		return "", false
+	}
+	return strings.TrimPrefix(data, " "), true
+}
+
+func (s *responseStream) streamError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
 }
@@ -0,0 +1,27 @@
+package provider
+
+import "testing"
+
+func TestHashPrompt(t *testing.T) {
+	messages := []Message{{Role: RoleUser, Content: "hello"}}
+
+	if HashPrompt("system", messages) != HashPrompt("system", messages) {
+		t.Error("expected identical prompts to hash equally")
+	}
+	if HashPrompt("system", messages) == HashPrompt("other", messages) {
+		t.Error("expected different system prompts to hash differently")
+	}
+}
+
+func TestSettingsMerge_Options(t *testing.T) {
+	defaults := Settings{Model: "mock", Options: map[string]string{"chunk_size": "4", "latency": "10ms"}}
+
+	merged := defaults.Merge(Settings{Options: map[string]string{"latency": "0s"}})
+
+	if merged.Options["chunk_size"] != "4" || merged.Options["latency"] != "0s" {
+		t.Errorf("expected options to be merged key by key, got %v", merged.Options)
+	}
+	if defaults.Options["latency"] != "10ms" {
+		t.Error("expected Merge to leave the receiver's options untouched")
+	}
+}
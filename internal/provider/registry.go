@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Settings is the configuration of a single provider, decoded from its section
+// of config.yaml (for example the "ollama:" block). RequestsPerMinute limits
+// how fast `gliik batch` sends requests to the provider; zero means no limit.
+// Options holds settings specific to one provider, such as the fixtures
+// directory of the mock provider.
+//
+// The API key is read from the output of APIKeyCmd, run with the shell, or
+// from the file APIKeyFile, and otherwise from the environment variable
+// APIKeyEnv.
+type Settings struct {
+	Endpoint          string            `yaml:"endpoint,omitempty"`
+	Model             string            `yaml:"model,omitempty"`
+	APIKeyEnv         string            `yaml:"api_key_env,omitempty"`
+	APIKeyFile        string            `yaml:"api_key_file,omitempty"`
+	APIKeyCmd         string            `yaml:"api_key_cmd,omitempty"`
+	RequestsPerMinute int               `yaml:"requests_per_minute,omitempty"`
+	Options           map[string]string `yaml:"options,omitempty"`
+}
+
+// Merge returns a copy of s where every non-empty field of override replaces
+// the corresponding field of s.
+func (s Settings) Merge(override Settings) Settings {
+	merged := s
+	if override.Endpoint != "" {
+		merged.Endpoint = override.Endpoint
+	}
+	if override.Model != "" {
+		merged.Model = override.Model
+	}
+	if override.APIKeyEnv != "" {
+		merged.APIKeyEnv = override.APIKeyEnv
+	}
+	if override.APIKeyFile != "" {
+		merged.APIKeyFile = override.APIKeyFile
+	}
+	if override.APIKeyCmd != "" {
+		merged.APIKeyCmd = override.APIKeyCmd
+	}
+	if override.RequestsPerMinute != 0 {
+		merged.RequestsPerMinute = override.RequestsPerMinute
+	}
+	if len(override.Options) > 0 {
+		merged.Options = make(map[string]string, len(s.Options)+len(override.Options))
+		for option, value := range s.Options {
+			merged.Options[option] = value
+		}
+		for option, value := range override.Options {
+			merged.Options[option] = value
+		}
+	}
+	return merged
+}
+
+// Registration describes a provider: the name used in config.yaml, the default
+// settings written by `gliik init` and filled in for missing keys, and the
+// constructor that builds the provider from its resolved settings and the HTTP
+// client it sends requests with. Validate checks the provider's Options when
+// config.yaml is loaded; providers without it take no options. Unlisted
+// providers, such as mock, get no section in a new config.yaml but can still
+// be selected by name.
+type Registration struct {
+	Name     string
+	Defaults Settings
+	New      func(settings Settings, client *http.Client) (LLMProvider, error)
+	Validate func(settings Settings) error
+	Unlisted bool
+}
+
+// Registry maps provider names to their registrations.
+type Registry struct {
+	registrations map[string]Registration
+}
+
+// DefaultRegistry holds the built-in providers. Each provider registers itself
+// from its own file.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{registrations: make(map[string]Registration)}
+}
+
+// Register adds a provider to the registry. It panics if the name is empty or
+// already registered, since registrations happen at program start.
+func (r *Registry) Register(registration Registration) {
+	if registration.Name == "" {
+		panic("provider: registration name cannot be empty")
+	}
+	if _, exists := r.registrations[registration.Name]; exists {
+		panic(fmt.Sprintf("provider: '%s' is already registered", registration.Name))
+	}
+	r.registrations[registration.Name] = registration
+}
+
+// Lookup returns the registration for name, or an error listing the valid
+// provider names.
+func (r *Registry) Lookup(name string) (Registration, error) {
+	registration, exists := r.registrations[name]
+	if !exists {
+		return Registration{}, fmt.Errorf("invalid provider '%s': must be one of %s", name, strings.Join(r.Names(), ", "))
+	}
+	return registration, nil
+}
+
+// Names returns the registered provider names in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.registrations))
+	for name := range r.registrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the provider registered under name, filling in the registered
+// defaults for any setting left empty in configured. A nil client uses
+// http.DefaultClient.
+func (r *Registry) New(name string, configured Settings, client *http.Client) (LLMProvider, error) {
+	registration, err := r.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return registration.New(registration.Defaults.Merge(configured), client)
+}
+
+// Validate checks the settings configured for the provider registered under
+// name, filling in the registered defaults first as New does.
+func (r *Registry) Validate(name string, configured Settings) error {
+	registration, err := r.Lookup(name)
+	if err != nil {
+		return err
+	}
+
+	settings := registration.Defaults.Merge(configured)
+	if registration.Validate != nil {
+		return registration.Validate(settings)
+	}
+	if len(settings.Options) > 0 {
+		return fmt.Errorf("provider '%s' takes no options", name)
+	}
+	return nil
+}
+
+// httpClient returns client, or http.DefaultClient when it is nil.
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"time"
+)
+
+// DefaultMaxAttempts is the number of attempts, including the first one,
+// made for a request when RetryPolicy.MaxAttempts is not set.
+const DefaultMaxAttempts = 3
+
+// RetryPolicy controls how RetryingProvider retries transient failures.
+// Zero values select the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// One disables retries.
+	MaxAttempts int
+	// BaseDelay is the upper bound of the wait before the first retry. It
+	// doubles with every further attempt, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// OnRetry, when set, is called before waiting to retry a failed attempt.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// RetryingProvider retries the requests of another LLMProvider that fail with
+// a retryable error (see IsRetryable), waiting a jittered, exponentially
+// growing delay or the delay requested by the provider's Retry-After header.
+// A request whose Retry-After exceeds MaxDelay is not retried. A request is
+// only retried while nothing has been written to the response writer, so
+// streamed output is never repeated.
+type RetryingProvider struct {
+	inner  LLMProvider
+	policy RetryPolicy
+	sleep  func(ctx context.Context, delay time.Duration) error
+}
+
+// NewRetryingProvider wraps inner with the given retry policy.
+func NewRetryingProvider(inner LLMProvider, policy RetryPolicy) *RetryingProvider {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultMaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = time.Second
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 30 * time.Second
+	}
+	return &RetryingProvider{inner: inner, policy: policy, sleep: Sleep}
+}
+
+// Complete sends req to the wrapped provider, retrying transient failures
+// that happen before the first byte of the response is written to w.
+func (p *RetryingProvider) Complete(ctx context.Context, req Request, w io.Writer) (Response, error) {
+	output := &countingWriter{writer: w}
+
+	for attempt := 1; ; attempt++ {
+		response, err := p.inner.Complete(ctx, req, output)
+		if err == nil || attempt >= p.policy.MaxAttempts || output.written > 0 || ctx.Err() != nil || !IsRetryable(err) {
+			return response, err
+		}
+
+		delay, retry := p.backoff(attempt, err)
+		if !retry {
+			return response, err
+		}
+		if p.policy.OnRetry != nil {
+			p.policy.OnRetry(attempt, delay, err)
+		}
+
+		if sleepErr := p.sleep(ctx, delay); sleepErr != nil {
+			return response, sleepErr
+		}
+	}
+}
+
+// backoff returns the delay before the retry following attempt: the
+// provider's Retry-After when given, otherwise a random delay between half
+// and all of BaseDelay doubled once per earlier attempt, capped at MaxDelay.
+// It reports false when Retry-After asks to wait longer than MaxDelay.
+func (p *RetryingProvider) backoff(attempt int, err error) (time.Duration, bool) {
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.RetryAfter > 0 {
+		return apiError.RetryAfter, apiError.RetryAfter <= p.policy.MaxDelay
+	}
+
+	ceiling := p.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.policy.MaxDelay {
+		ceiling = p.policy.MaxDelay
+	}
+	return ceiling/2 + rand.N(ceiling/2+1), true
+}
+
+// Sleep waits for delay to pass, returning early with ctx's error when ctx is
+// done first.
+func Sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += n
+	return n, err
+}
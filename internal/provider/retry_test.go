@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type scriptedProvider struct {
+	errors  []error
+	partial string
+	calls   int
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, req Request, w io.Writer) (Response, error) {
+	p.calls++
+	if p.calls <= len(p.errors) {
+		if p.partial != "" {
+			io.WriteString(w, p.partial)
+		}
+		return Response{}, p.errors[p.calls-1]
+	}
+	io.WriteString(w, "done")
+	return Response{Text: "done"}, nil
+}
+
+func newTestRetryingProvider(inner LLMProvider, maxAttempts int, delays *[]time.Duration) *RetryingProvider {
+	retrying := NewRetryingProvider(inner, RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Second, MaxDelay: 4 * time.Second})
+	retrying.sleep = func(ctx context.Context, delay time.Duration) error {
+		*delays = append(*delays, delay)
+		return nil
+	}
+	return retrying
+}
+
+func TestRetryingProvider_RetriesTransientErrors(t *testing.T) {
+	inner := &scriptedProvider{errors: []error{
+		&APIError{StatusCode: http.StatusTooManyRequests},
+		&APIError{StatusCode: StatusOverloaded},
+	}}
+	var delays []time.Duration
+
+	var output strings.Builder
+	response, err := newTestRetryingProvider(inner, 3, &delays).Complete(context.Background(), Request{}, &output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if inner.calls != 3 || response.Text != "done" || output.String() != "done" {
+		t.Errorf("expected success on third attempt, got %d calls and %q", inner.calls, output.String())
+	}
+
+	if len(delays) != 2 {
+		t.Fatalf("expected 2 waits, got %v", delays)
+	}
+	if delays[0] < 500*time.Millisecond || delays[0] > time.Second {
+		t.Errorf("expected first delay between 0.5s and 1s, got %s", delays[0])
+	}
+	if delays[1] < time.Second || delays[1] > 2*time.Second {
+		t.Errorf("expected second delay between 1s and 2s, got %s", delays[1])
+	}
+}
+
+func TestRetryingProvider_HonorsRetryAfter(t *testing.T) {
+	inner := &scriptedProvider{errors: []error{&APIError{StatusCode: http.StatusServiceUnavailable, RetryAfter: 3 * time.Second}}}
+	var delays []time.Duration
+
+	if _, err := newTestRetryingProvider(inner, 3, &delays).Complete(context.Background(), Request{}, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(delays) != 1 || delays[0] != 3*time.Second {
+		t.Errorf("expected a 3s wait from Retry-After, got %v", delays)
+	}
+}
+
+func TestRetryingProvider_GivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	failure := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 24 * time.Hour}
+	inner := &scriptedProvider{errors: []error{failure}}
+	var delays []time.Duration
+
+	_, err := newTestRetryingProvider(inner, 3, &delays).Complete(context.Background(), Request{}, io.Discard)
+	if !errors.Is(err, failure) || inner.calls != 1 || len(delays) != 0 {
+		t.Errorf("expected a single attempt without waiting, got %d calls, waits %v and error %v", inner.calls, delays, err)
+	}
+}
+
+func TestRetryingProvider_StopsAfterMaxAttempts(t *testing.T) {
+	failure := &APIError{StatusCode: http.StatusBadGateway, Body: "bad gateway"}
+	inner := &scriptedProvider{errors: []error{failure, failure, failure}}
+	var delays []time.Duration
+
+	_, err := newTestRetryingProvider(inner, 2, &delays).Complete(context.Background(), Request{}, io.Discard)
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", inner.calls)
+	}
+}
+
+func TestRetryingProvider_DoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		partial string
+	}{
+		{"client error", &APIError{StatusCode: http.StatusUnauthorized}, ""},
+		{"other error", errors.New("failed to marshal request"), ""},
+		{"after output was written", &APIError{StatusCode: http.StatusServiceUnavailable}, "partial"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &scriptedProvider{errors: []error{tt.err}, partial: tt.partial}
+			var delays []time.Duration
+
+			_, err := newTestRetryingProvider(inner, 3, &delays).Complete(context.Background(), Request{}, io.Discard)
+			if err == nil || inner.calls != 1 {
+				t.Errorf("expected a single failed attempt, got %d calls and error %v", inner.calls, err)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"rate limited", &APIError{StatusCode: 429}, true},
+		{"server error", &APIError{StatusCode: 500}, true},
+		{"bad gateway", &APIError{StatusCode: 502}, true},
+		{"unavailable", &APIError{StatusCode: 503}, true},
+		{"overloaded", &APIError{StatusCode: 529}, true},
+		{"not found", &APIError{StatusCode: 404}, false},
+		{"connection reset", &netWrappedError{syscall.ECONNRESET}, true},
+		{"truncated stream", io.ErrUnexpectedEOF, true},
+		{"streamed rate limit", newStreamAPIError("openai", `{"code":"rate_limit_exceeded"}`), true},
+		{"streamed error", newStreamAPIError("ollama", "model not found"), false},
+		{"anthropic overloaded event", &AnthropicStreamError{Type: "overloaded_error"}, true},
+		{"anthropic invalid request event", &AnthropicStreamError{Type: "invalid_request_error"}, false},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Duration{
+		"":                              0,
+		"12":                            12 * time.Second,
+		"-3":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+
+	for value, expected := range tests {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("parseRetryAfter(%q): expected %s, got %s", value, expected, got)
+		}
+	}
+}
+
+type netWrappedError struct {
+	err error
+}
+
+func (e *netWrappedError) Error() string {
+	return "read tcp: " + e.err.Error()
+}
+
+func (e *netWrappedError) Unwrap() error {
+	return e.err
+}
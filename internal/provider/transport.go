@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Default timeouts of the HTTP client built by NewHTTPClient. Responses are
+// streamed, so no timeout limits a whole request; IdleStreamTimeout limits the
+// wait between two reads of a response body instead.
+const (
+	DefaultConnectTimeout        = 30 * time.Second
+	DefaultResponseHeaderTimeout = 5 * time.Minute
+	DefaultIdleStreamTimeout     = 5 * time.Minute
+)
+
+// TransportSettings configures the HTTP client shared by the providers,
+// decoded from the "transport:" section of config.yaml. Timeouts are Go
+// durations such as "30s"; an empty timeout uses its default and "0"
+// disables it. Headers are added to every request that does not already set
+// them.
+type TransportSettings struct {
+	ConnectTimeout        string            `yaml:"connect_timeout,omitempty"`
+	ResponseHeaderTimeout string            `yaml:"response_header_timeout,omitempty"`
+	IdleStreamTimeout     string            `yaml:"idle_stream_timeout,omitempty"`
+	ProxyURL              string            `yaml:"proxy_url,omitempty"`
+	CAFile                string            `yaml:"ca_file,omitempty"`
+	InsecureSkipVerify    bool              `yaml:"insecure_skip_verify,omitempty"`
+	Headers               map[string]string `yaml:"headers,omitempty"`
+}
+
+// NewHTTPClient builds the HTTP client described by settings. Without a
+// proxy_url, proxies are taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+// environment variables. A ca_file is trusted in addition to the system
+// certificates.
+func NewHTTPClient(settings TransportSettings) (*http.Client, error) {
+	connectTimeout, err := parseTimeout("connect_timeout", settings.ConnectTimeout, DefaultConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	responseHeaderTimeout, err := parseTimeout("response_header_timeout", settings.ResponseHeaderTimeout, DefaultResponseHeaderTimeout)
+	if err != nil {
+		return nil, err
+	}
+	idleStreamTimeout, err := parseTimeout("idle_stream_timeout", settings.IdleStreamTimeout, DefaultIdleStreamTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+
+	if settings.ProxyURL != "" {
+		proxyURL, err := url.Parse(settings.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid transport proxy_url '%s': must be a URL such as http://proxy.example.com:3128", settings.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if settings.CAFile != "" || settings.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+	}
+
+	if settings.CAFile != "" {
+		pool, err := loadCertificatePool(settings.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	return &http.Client{Transport: &headerTransport{
+		inner:             transport,
+		headers:           settings.Headers,
+		idleStreamTimeout: idleStreamTimeout,
+	}}, nil
+}
+
+func parseTimeout(name string, value string, defaultTimeout time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultTimeout, nil
+	}
+	if value == "0" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid transport %s '%s': must be a duration such as 30s, or 0 to disable it", name, value)
+	}
+	return timeout, nil
+}
+
+func loadCertificatePool(caFile string) (*x509.CertPool, error) {
+	if rest, found := strings.CutPrefix(caFile, "~/"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			caFile = filepath.Join(home, rest)
+		}
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transport ca_file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("transport ca_file %s contains no PEM certificates", caFile)
+	}
+	return pool, nil
+}
+
+// headerTransport adds the configured headers to each request and limits how
+// long a response body may go without data.
+type headerTransport struct {
+	inner             http.RoundTripper
+	headers           map[string]string
+	idleStreamTimeout time.Duration
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) > 0 {
+		req = req.Clone(req.Context())
+		for header, value := range t.headers {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, value)
+			}
+		}
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil || t.idleStreamTimeout <= 0 {
+		return resp, err
+	}
+
+	resp.Body = newIdleTimeoutBody(resp.Body, t.idleStreamTimeout)
+	return resp, nil
+}
+
+// idleTimeoutBody closes a response body that receives no data for longer
+// than timeout, so a stalled stream fails instead of blocking forever.
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	idle := &idleTimeoutBody{body: body, timeout: timeout}
+	idle.timer = time.AfterFunc(timeout, func() {
+		idle.expired.Store(true)
+		body.Close()
+	})
+	return idle
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.expired.Load() {
+		return n, fmt.Errorf("no data received for %s: %w", b.timeout, os.ErrDeadlineExceeded)
+	}
+	b.timer.Reset(b.timeout)
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.body.Close()
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient_AddsHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(TransportSettings{Headers: map[string]string{"X-Team": "research", "Authorization": "Bearer configured"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Authorization", "Bearer provider")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if received.Get("X-Team") != "research" {
+		t.Errorf("expected the configured header, got %q", received.Get("X-Team"))
+	}
+	if received.Get("Authorization") != "Bearer provider" {
+		t.Errorf("expected the provider's own header to be kept, got %q", received.Get("Authorization"))
+	}
+}
+
+func TestNewHTTPClient_IdleStreamTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "data: first\n")
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewHTTPClient(TransportSettings{IdleStreamTimeout: "50ms"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected an idle timeout error, got %v", err)
+	}
+}
+
+func TestNewHTTPClient_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certificate, 0644); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	untrusted, _ := NewHTTPClient(TransportSettings{})
+	if _, err := untrusted.Get(server.URL); err == nil {
+		t.Error("expected the test server's certificate to be rejected without ca_file")
+	}
+
+	trusted, err := NewHTTPClient(TransportSettings{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := trusted.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the certificate to be trusted with ca_file, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClient_ProxyURL(t *testing.T) {
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(TransportSettings{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Get("http://api.example.com/v1/models")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if proxiedURL != "http://api.example.com/v1/models" {
+		t.Errorf("expected the request to go through the proxy, got %q", proxiedURL)
+	}
+}
+
+func TestNewHTTPClient_InvalidSettings(t *testing.T) {
+	tests := map[string]TransportSettings{
+		"invalid timeout":   {ConnectTimeout: "soon"},
+		"negative timeout":  {IdleStreamTimeout: "-1s"},
+		"invalid proxy":     {ProxyURL: "proxy:3128"},
+		"missing CA file":   {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA file not a PEM": {CAFile: writeTempFile(t, "not a certificate")},
+	}
+
+	for name, settings := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewHTTPClient(settings); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    time.Minute,
+		"0":   0,
+		"90s": 90 * time.Second,
+	}
+
+	for value, expected := range tests {
+		timeout, err := parseTimeout("connect_timeout", value, time.Minute)
+		if err != nil || timeout != expected {
+			t.Errorf("parseTimeout(%q): expected %s, got %s (%v)", value, expected, timeout, err)
+		}
+	}
+}
+
+func writeTempFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	return path
+}
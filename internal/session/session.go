@@ -0,0 +1,153 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/gliik/internal/atomicfile"
+	"github.com/yourusername/gliik/internal/config"
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+// Session is a saved conversation that later runs and chats can append to.
+// Provider and Model record the ones used for the most recent turn.
+// FirstReplyLength is the number of messages up to and including the first
+// reply, which /reset in a resumed chat returns to.
+type Session struct {
+	Name               string             `json:"name"`
+	Instruction        string             `json:"instruction"`
+	InstructionVersion string             `json:"instruction_version"`
+	Variables          map[string]string  `json:"variables,omitempty"`
+	Provider           string             `json:"provider"`
+	Model              string             `json:"model"`
+	System             string             `json:"system,omitempty"`
+	Messages           []provider.Message `json:"messages"`
+	FirstReplyLength   int                `json:"first_reply_length,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+var validNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateName checks that name can be used as a session file name.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("session name cannot be empty")
+	}
+	if !validNameRegex.MatchString(name) {
+		return fmt.Errorf("session name must contain only alphanumeric characters, underscores and hyphens")
+	}
+	return nil
+}
+
+// GetSessionsDir returns the directory holding one JSON file per session.
+func GetSessionsDir() string {
+	return filepath.Join(config.GetGliikHome(), "sessions")
+}
+
+func sessionFile(name string) string {
+	return filepath.Join(GetSessionsDir(), name+".json")
+}
+
+// Load reads the session called name. The returned error wraps os.ErrNotExist
+// when the session does not exist.
+func Load(name string) (*Session, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(sessionFile(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("session '%s' not found: %w", name, err)
+		}
+		return nil, fmt.Errorf("failed to read session '%s': %w", name, err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session '%s': %w", name, err)
+	}
+
+	return &session, nil
+}
+
+// Save writes the session atomically, setting CreatedAt on first save and
+// UpdatedAt on every save.
+func (s *Session) Save() error {
+	if err := ValidateName(s.Name); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.UpdatedAt = now
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := os.MkdirAll(GetSessionsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	return atomicfile.Write(sessionFile(s.Name), data)
+}
+
+// List returns all saved sessions, most recently updated first.
+func List() ([]Session, error) {
+	entries, err := os.ReadDir(GetSessionsDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	var sessions []Session
+	for _, entry := range entries {
+		name, isSessionFile := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !isSessionFile {
+			continue
+		}
+
+		session, err := Load(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
+	return sessions, nil
+}
+
+// Remove deletes the session called name.
+func Remove(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(sessionFile(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("session '%s' not found", name)
+		}
+		return fmt.Errorf("failed to remove session: %w", err)
+	}
+
+	return nil
+}
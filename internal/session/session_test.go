@@ -0,0 +1,116 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yourusername/gliik/internal/provider"
+)
+
+func TestSession_SaveAndLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	session := &Session{
+		Name:               "bugfix",
+		Instruction:        "review",
+		InstructionVersion: "1.2.0",
+		Variables:          map[string]string{"{{lang}}": "go"},
+		Provider:           "anthropic",
+		Model:              "claude-sonnet-4-20250514",
+		System:             "You review code.",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "func main() {}"},
+			{Role: provider.RoleAssistant, Content: "Looks fine."},
+		},
+	}
+
+	if err := session.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if session.CreatedAt.IsZero() || session.UpdatedAt.IsZero() {
+		t.Error("expected timestamps to be set on save")
+	}
+
+	loaded, err := Load("bugfix")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if loaded.Instruction != "review" || loaded.InstructionVersion != "1.2.0" {
+		t.Errorf("expected instruction review v1.2.0, got %s v%s", loaded.Instruction, loaded.InstructionVersion)
+	}
+
+	if loaded.Variables["{{lang}}"] != "go" {
+		t.Errorf("expected variable '{{lang}}' to be 'go', got '%s'", loaded.Variables["{{lang}}"])
+	}
+
+	if len(loaded.Messages) != 2 || loaded.Messages[1].Content != "Looks fine." {
+		t.Errorf("expected 2 messages ending with the reply, got %+v", loaded.Messages)
+	}
+}
+
+func TestLoad_MissingSession(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	_, err := Load("missing")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected error wrapping os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestList_SortsByUpdatedAt(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	for _, name := range []string{"first", "second"} {
+		session := &Session{Name: name, Instruction: "summarize"}
+		if err := session.Save(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	sessions, err := List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+
+	if sessions[0].Name != "second" {
+		t.Errorf("expected most recent session first, got '%s'", sessions[0].Name)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	session := &Session{Name: "old", Instruction: "summarize"}
+	if err := session.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := Remove("old"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := Remove("old"); err == nil {
+		t.Error("expected error when removing a missing session")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"bugfix", "bug-fix_2"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("expected '%s' to be valid, got %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "../escape", "with space"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("expected '%s' to be invalid", name)
+		}
+	}
+}
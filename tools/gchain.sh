@@ -8,9 +8,8 @@
 # Example:
 #   gchain context.md analyze_metrics --output report.md
 #
-# This tool chains two Gliik instructions:
-# 1. select_context: Determines which linked files are relevant
-# 2. <instruction>: Executes with selected context files
+# This tool is a shortcut for:
+#   gliik run <instruction> --context <context-file> --context-selector select_context
 
 set -euo pipefail
 
@@ -45,57 +44,4 @@ CONTEXT_FILE="$1"
 INSTRUCTION="$2"
 shift 2
 
-# Validate context file exists
-if [ ! -f "$CONTEXT_FILE" ]; then
-    echo "Error: Context file not found: $CONTEXT_FILE" >&2
-    exit 1
-fi
-
-# Get directory of context file for resolving relative paths
-CONTEXT_DIR=$(dirname "$CONTEXT_FILE")
-
-# Execute select_context to get list of needed files
-echo "→ Selecting relevant context files..." >&2
-SELECTED_FILES=$(cat "$CONTEXT_FILE" | gliik run select_context --intent "$INSTRUCTION" "$@" 2>/dev/null)
-
-if [ -z "$SELECTED_FILES" ]; then
-    echo "→ No additional context files selected" >&2
-    cat "$CONTEXT_FILE" | gliik run "$INSTRUCTION" "$@"
-    exit 0
-fi
-
-# Display selected files
-echo "→ Selected context files:" >&2
-echo "$SELECTED_FILES" | sed 's/^/  - /' >&2
-
-# Resolve paths relative to context file directory and validate
-RESOLVED_FILES=""
-while IFS= read -r file; do
-    # Skip empty lines
-    [ -z "$file" ] && continue
-    
-    # Resolve relative path
-    if [[ "$file" = /* ]]; then
-        # Absolute path
-        FULL_PATH="$file"
-    else
-        # Relative path - resolve relative to context file directory
-        FULL_PATH="$CONTEXT_DIR/$file"
-    fi
-    
-    if [ ! -f "$FULL_PATH" ]; then
-        echo "Warning: Selected file not found: $FULL_PATH" >&2
-        continue
-    fi
-    
-    RESOLVED_FILES="$RESOLVED_FILES $FULL_PATH"
-done <<< "$SELECTED_FILES"
-
-# Execute instruction with combined context
-echo "→ Executing instruction: $INSTRUCTION" >&2
-if [ -n "$RESOLVED_FILES" ]; then
-    cat "$CONTEXT_FILE" $RESOLVED_FILES | gliik run "$INSTRUCTION" "$@"
-else
-    # No valid files found, use just context file
-    cat "$CONTEXT_FILE" | gliik run "$INSTRUCTION" "$@"
-fi
+exec gliik run "$INSTRUCTION" --context "$CONTEXT_FILE" --context-selector select_context "$@"